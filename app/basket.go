@@ -0,0 +1,241 @@
+package app
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/epainos/gofuli/cmdline"
+	"github.com/epainos/gofuli/filer"
+	"github.com/epainos/gofuli/message"
+)
+
+// BasketAdd adds marked files to the basket collecting files across directories.
+func (g *Goful) BasketAdd() {
+	if g.Dir().IsRemote() {
+		message.Errorf("Basket is not supported in %s", g.Dir().Path)
+		return
+	}
+	n := filer.AddBasket(g.Dir().MarkfilePaths()...)
+	g.Dir().MarkClear()
+	message.Infof("Added %d files to the basket (%d)", n, len(filer.BasketPaths()))
+}
+
+// BasketRemove removes marked files from the basket.
+func (g *Goful) BasketRemove() {
+	filer.RemoveBasket(g.Dir().MarkfilePaths()...)
+	message.Infof("Basket has %d files", len(filer.BasketPaths()))
+	g.reloadBasket()
+}
+
+// BasketClear removes all files from the basket.
+func (g *Goful) BasketClear() {
+	filer.ClearBasket()
+	message.Info("Cleared the basket")
+	g.reloadBasket()
+}
+
+func (g *Goful) reloadBasket() {
+	if g.Dir().IsBasket() {
+		g.Dir().ShowBasket()
+	}
+}
+
+// removeGone removes paths moved or removed from the basket, and keeps
+// paths failed or skipped.
+func (g *Goful) removeGone(paths []string, err error) {
+	filer.RemoveBasket(gonePaths(paths, err)...)
+	g.reloadBasket()
+}
+
+// basketFiles returns paths in the basket or shows an error if empty.
+func basketFiles() []string {
+	paths := filer.BasketPaths()
+	if len(paths) == 0 {
+		message.Info("Basket is empty")
+	}
+	return paths
+}
+
+// BasketCopy copies files in the basket to the directory.
+func (g *Goful) BasketCopy() {
+	if !writable() {
+		return
+	}
+	paths := basketFiles()
+	if len(paths) == 0 {
+		return
+	}
+	dst := g.Dir().Path
+	switch g.dialog(fmt.Sprintf("Copy %d files in the basket to %s?", len(paths), dst), "y", "n") {
+	case "y":
+		g.copy(dst, paths...)
+	}
+}
+
+// BasketMove moves files in the basket to the directory and removes moved
+// files from the basket.
+func (g *Goful) BasketMove() {
+	if !writable() {
+		return
+	}
+	paths := basketFiles()
+	if len(paths) == 0 {
+		return
+	}
+	dst := g.Dir().Path
+	switch g.dialog(fmt.Sprintf("Move %d files in the basket to %s?", len(paths), dst), "y", "n") {
+	case "y":
+		g.moveThen(func(err error) { g.removeGone(paths, err) }, dst, paths...)
+	}
+}
+
+// BasketDelete removes files in the basket permanently and removes them from
+// the basket.
+func (g *Goful) BasketDelete() {
+	if !writable() {
+		return
+	}
+	paths := basketFiles()
+	if len(paths) == 0 {
+		return
+	}
+	switch g.dialog(fmt.Sprintf("Remove permanently %d files in the basket?", len(paths)), "y", "n") {
+	case "y":
+		g.removeThen(func(err error) { g.removeGone(paths, err) }, paths...)
+	}
+}
+
+// BasketArchive starts the mode to archive files in the basket to a zip
+// file in the directory.
+func (g *Goful) BasketArchive() {
+	if !writable() {
+		return
+	}
+	if len(basketFiles()) == 0 {
+		return
+	}
+	c := cmdline.New(&basketArchiveMode{g}, g)
+	c.SetText("basket.zip")
+	g.next = c
+}
+
+type basketArchiveMode struct {
+	*Goful
+}
+
+func (m *basketArchiveMode) String() string { return "basketarchive" }
+func (m *basketArchiveMode) Prompt() string {
+	return fmt.Sprintf("Archive %d files in the basket(바구니 압축) -> ", len(filer.BasketPaths()))
+}
+func (m *basketArchiveMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *basketArchiveMode) Run(c *cmdline.Cmdline) {
+	name := c.String()
+	if name == "" {
+		return
+	}
+	dst := absPath(name)
+	if isRemote(dst) {
+		message.Errorf("Archive is not supported in %s", dst)
+		return
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		message.Errorf("%s already exists", name)
+		return
+	}
+	paths := filer.BasketPaths()
+	m.asyncFilectrl(func() {
+		if err := archiveZip(dst, paths...); err != nil {
+			message.Error(err)
+		} else {
+			message.Infof("Archived %d files to %s", len(paths), dst)
+		}
+	})
+	c.Exit()
+}
+
+// archiveZip creates the zip file of files and directories named by the base
+// names.
+func archiveZip(dst string, paths ...string) error {
+	file, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(file)
+	names := map[string]bool{}
+	for _, path := range paths {
+		base := filepath.Dir(path)
+		err = filepath.Walk(path, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if name == dst {
+				return nil // the zip file being written in the directory
+			}
+			return addZip(zw, names, base, name, info)
+		})
+		if err != nil {
+			break
+		}
+	}
+	if e := zw.Close(); err == nil {
+		err = e
+	}
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return err
+}
+
+// addZip writes the file named relative to the base. The names already
+// written are shared directories or fail as conflicting files.
+func addZip(zw *zip.Writer, names map[string]bool, base, name string, info os.FileInfo) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(base, name)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(rel)
+	if info.IsDir() {
+		header.Name += "/"
+	} else {
+		header.Method = zip.Deflate
+	}
+	if names[header.Name] {
+		if info.IsDir() {
+			return nil
+		}
+		return fmt.Errorf("duplicate name %s in the zip: %s", header.Name, name)
+	}
+	names[header.Name] = true
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, link)
+		return err
+	case info.Mode().IsRegular():
+		src, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(w, src)
+		return err
+	}
+	return nil
+}
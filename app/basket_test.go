@@ -0,0 +1,58 @@
+package app
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveZip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gofuli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a/x.txt", "b/x.txt", "c/sub/y.txt", "d/sub/z.txt"} {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(dir, "same.zip")
+	if err := archiveZip(dst, filepath.Join(dir, "a", "x.txt"), filepath.Join(dir, "b", "x.txt")); err == nil {
+		t.Errorf("archiveZip(a/x.txt, b/x.txt)=nil, want error")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("archiveZip(a/x.txt, b/x.txt) left %s", dst)
+	}
+
+	dst = filepath.Join(dir, "merged.zip")
+	if err := archiveZip(dst, filepath.Join(dir, "c", "sub"), filepath.Join(dir, "d", "sub")); err != nil {
+		t.Fatalf("archiveZip(c/sub, d/sub)=%v, want nil", err)
+	}
+	zr, err := zip.OpenReader(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want := []string{"sub/", "sub/y.txt", "sub/z.txt"}
+	if len(names) != len(want) {
+		t.Fatalf("archiveZip(c/sub, d/sub) wrote %q, want %q", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("archiveZip(c/sub, d/sub) wrote %q, want %q", names, want)
+			break
+		}
+	}
+}
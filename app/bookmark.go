@@ -0,0 +1,332 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/epainos/gofuli/bookmark"
+	"github.com/epainos/gofuli/cmdline"
+	"github.com/epainos/gofuli/menu"
+	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/util"
+)
+
+// Menus of bookmarked directories and applications.
+const (
+	bookmarkMenu = "myBookmark"
+	appMenu      = "myApp"
+)
+
+// reservedKeys are keys of the menu items adding, deleting and editing.
+var reservedKeys = []string{"+", "-", "="}
+
+// bookmarks is the store and acceleration keys added to menus by the store.
+var bookmarks = struct {
+	path  string
+	err   error // the load error not to overwrite the file
+	store *bookmark.Store
+	menus map[string][]string
+}{store: &bookmark.Store{}, menus: map[string][]string{}}
+
+// LoadBookmarks loads bookmarks from the json file and adds them to menus.
+// Old myBookmark and myApp files in the same directory are migrated if the
+// file does not exist.
+func (g *Goful) LoadBookmarks(path string) {
+	path = util.ExpandPath(path)
+	dir := filepath.Dir(path)
+	bookmarks.path = path
+	store, err := bookmark.Load(path, filepath.Join(dir, bookmarkMenu), filepath.Join(dir, appMenu))
+	bookmarks.err = err
+	if err != nil {
+		message.Error(err)
+	}
+	if store != nil {
+		bookmarks.store = store
+	}
+	g.addBookmarkMenus()
+	menu.ConfigRemover(g.removeBookmark)
+}
+
+// addBookmarkMenus adds items of the store to menus replacing items added before.
+func (g *Goful) addBookmarkMenus() {
+	for name, accels := range bookmarks.menus {
+		for _, accel := range accels {
+			menu.Remove(name, accel)
+		}
+	}
+	bookmarks.menus = map[string][]string{}
+	add := func(name, accel, label string, callback func()) {
+		menu.Add(name, accel, label, callback)
+		bookmarks.menus[name] = append(bookmarks.menus[name], accel)
+	}
+	roots := append([]string{}, reservedKeys...) // keys of the bookmark menu
+	for _, group := range bookmarks.store.Groups {
+		if group.Name == bookmarkMenu {
+			for _, item := range group.Items {
+				roots = append(roots, item.Key)
+			}
+		}
+	}
+	for _, group := range bookmarks.store.Groups {
+		for _, item := range group.Items {
+			item := item
+			label := item.Name
+			if item.Description != "" {
+				label = fmt.Sprintf("%-20s %s", item.Name, item.Description)
+			}
+			add(group.Name, item.Key, label, func() { g.openBookmark(item) })
+		}
+		if name := group.Name; group.Key != "" && name != bookmarkMenu && name != appMenu {
+			if err := checkKeys(roots, group.Key); err != nil {
+				message.Errorf("%s: %v", name, err)
+				continue
+			}
+			roots = append(roots, group.Key)
+			add(bookmarkMenu, group.Key, name+"/", func() { g.Menu(name) })
+		}
+	}
+}
+
+// openBookmark spawns the command or changes to the path in the pane
+// specified by the item.
+func (g *Goful) openBookmark(item *bookmark.Item) {
+	if item.Command != "" {
+		g.Spawn(item.Command)
+		return
+	}
+	switch item.Open {
+	case bookmark.OpenTab:
+		g.CreateWorkspace()
+	case bookmark.OpenPane:
+		g.Workspace().CreateDir()
+	}
+	g.Dir().Chdir(item.Path)
+}
+
+// updateBookmarks changes the store and saves it, and updates menus.
+func (g *Goful) updateBookmarks(update func(s *bookmark.Store) error) error {
+	if bookmarks.err != nil {
+		return fmt.Errorf("Fix the error of loading not to overwrite bookmarks(로딩 오류를 고쳐주세요): %v", bookmarks.err)
+	}
+	store := bookmarks.store.Clone() // kept unchanged if failed to save
+	if err := update(store); err != nil {
+		return err
+	}
+	if bookmarks.path != "" {
+		if err := store.Save(bookmarks.path); err != nil {
+			return err
+		}
+	}
+	bookmarks.store = store
+	g.addBookmarkMenus()
+	return nil
+}
+
+// removeBookmark removes the item of the menu by the delete key.
+func (g *Goful) removeBookmark(name, accel string) error {
+	if checkKeys(reservedKeys, accel) != nil {
+		return fmt.Errorf("'add', 'del', 'edit' cannot be removed... 추가,삭제,수정은 삭제할 수 없어요.")
+	}
+	if bookmarks.store.Find(name, accel) == nil {
+		return fmt.Errorf("Default menu cannot be removed... 기본 메뉴는 삭제할 수 없어요.")
+	}
+	return g.updateBookmarks(func(s *bookmark.Store) error { return s.Delete(name, accel) })
+}
+
+// AddMyBookmark starts the mode to bookmark the directory.
+func (g *Goful) AddMyBookmark() {
+	g.editBookmark(bookmarkMenu, "", &bookmark.Item{Path: filepath.ToSlash(g.Dir().Path)})
+}
+
+// AddMyApp starts the mode to add the file on the cursor as the application.
+func (g *Goful) AddMyApp() {
+	open := ifElseSting(runtime.GOOS == "windows", `start`, ifElseSting(runtime.GOOS == "darwin", `open -a`, ""))
+	g.editBookmark(appMenu, "", &bookmark.Item{Command: open + ` '` + g.File().Path() + `' %F`})
+}
+
+// EditMyBookmark starts the mode to edit the bookmark of the key.
+func (g *Goful) EditMyBookmark() { g.selectBookmark(bookmarkMenu, false) }
+
+// EditMyApp starts the mode to edit the application of the key.
+func (g *Goful) EditMyApp() { g.selectBookmark(appMenu, false) }
+
+// DelMyBookmark starts the mode to delete the bookmark of the key.
+func (g *Goful) DelMyBookmark() { g.selectBookmark(bookmarkMenu, true) }
+
+// DelMyApp starts the mode to delete the application of the key.
+func (g *Goful) DelMyApp() { g.selectBookmark(appMenu, true) }
+
+func (g *Goful) editBookmark(group, old string, item *bookmark.Item) {
+	c := cmdline.New(&bookmarkMode{Goful: g, group: group, old: old, item: item}, g)
+	c.SetText(item.Path + item.Command)
+	g.next = c
+}
+
+// bookmarkMode inputs the path or the command, the name, the key, the
+// description and where to open the bookmark in order.
+type bookmarkMode struct {
+	*Goful
+	group string
+	old   string // the key of the editing item
+	item  *bookmark.Item
+	step  int
+}
+
+func (m *bookmarkMode) String() string { return "bookmark" }
+func (m *bookmarkMode) Prompt() string {
+	switch m.step {
+	case 0:
+		if m.item.Command != "" || m.group == appMenu {
+			return "App command(사용자앱 명령): "
+		}
+		return "Bookmark path(바로가기 경로): "
+	case 1:
+		return "Name(이름): "
+	case 2:
+		return fmt.Sprintf("Key for '%s'(단축키): ", m.item.Name)
+	case 3:
+		return "Description(설명): "
+	}
+	return "Open in(열 곳) [empty, tab, pane]: "
+}
+func (m *bookmarkMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *bookmarkMode) Run(c *cmdline.Cmdline) {
+	text := c.String()
+	switch m.step {
+	case 0:
+		if text == "" {
+			return
+		}
+		if m.item.Command != "" || m.group == appMenu {
+			m.item.Command = text
+		} else {
+			m.item.Path = text
+		}
+		if m.item.Name == "" {
+			m.item.Name = strings.ReplaceAll(myfGetLastWord(strings.TrimSuffix(text, " %F")), `'`, ``)
+		}
+		c.SetText(m.item.Name)
+	case 1:
+		m.item.Name = text
+		c.SetText(m.item.Key)
+	case 2:
+		if err := m.checkKey(text); err != nil {
+			message.Error(err)
+			return
+		}
+		m.item.Key = text
+		c.SetText(m.item.Description)
+	case 3:
+		m.item.Description = text
+		if m.item.Command != "" {
+			m.save(c)
+			return
+		}
+		c.SetText(m.item.Open)
+	default:
+		m.item.Open = text
+		m.save(c)
+		return
+	}
+	m.step++
+}
+
+func (m *bookmarkMode) checkKey(key string) error {
+	if err := checkKeys(reservedKeys, key); err != nil {
+		return fmt.Errorf("%v reserved", err)
+	}
+	if m.group == bookmarkMenu {
+		groups := []string{}
+		for _, group := range bookmarks.store.Groups {
+			if group.Key != "" && group.Name != bookmarkMenu && group.Name != appMenu {
+				groups = append(groups, group.Key)
+			}
+		}
+		if err := checkKeys(groups, key); err != nil {
+			return fmt.Errorf("%v of a group", err)
+		}
+	}
+	return bookmarks.store.CheckKey(m.group, m.old, key)
+}
+
+// checkKeys returns an error if the key conflicts with one of the keys.
+func checkKeys(keys []string, key string) error {
+	for _, k := range keys {
+		if err := bookmark.Conflict(k, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *bookmarkMode) save(c *cmdline.Cmdline) {
+	err := m.updateBookmarks(func(s *bookmark.Store) error { return s.Add(m.group, m.old, m.item) })
+	if err != nil {
+		message.Error(err)
+		return
+	}
+	message.Infof("Saved %s (%s)", m.item.Name, m.item.Key)
+	c.Exit()
+}
+
+func (g *Goful) selectBookmark(group string, remove bool) {
+	g.next = cmdline.New(&selectBookmarkMode{Goful: g, group: group, remove: remove}, g)
+}
+
+// selectBookmarkMode inputs the key of the item to edit or to delete.
+type selectBookmarkMode struct {
+	*Goful
+	group  string
+	remove bool
+	key    string // the key to delete confirming
+}
+
+func (m *selectBookmarkMode) String() string { return "selectbookmark" }
+func (m *selectBookmarkMode) Prompt() string {
+	if m.key != "" {
+		return fmt.Sprintf("Delete '%s'? (삭제) [y/n]: ", bookmarks.store.Find(m.group, m.key).Name)
+	}
+	keys := []string{}
+	if group := bookmarks.store.Lookup(m.group); group != nil {
+		for _, item := range group.Items {
+			keys = append(keys, item.Key)
+		}
+	}
+	if len(keys) > 10 {
+		keys = append(keys[:9], "...")
+	}
+	if m.remove {
+		return "Key to delete(지울 단축키) " + strings.Join(keys, ", ") + ": "
+	}
+	return "Key to edit(고칠 단축키) " + strings.Join(keys, ", ") + ": "
+}
+func (m *selectBookmarkMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *selectBookmarkMode) Run(c *cmdline.Cmdline) {
+	if m.key != "" {
+		if text := c.String(); text == "y" || text == "Y" || text == "" {
+			if err := m.updateBookmarks(func(s *bookmark.Store) error { return s.Delete(m.group, m.key) }); err != nil {
+				message.Error(err)
+			} else {
+				message.Info("Deleted: " + m.key)
+			}
+		}
+		c.Exit()
+		return
+	}
+	item := bookmarks.store.Find(m.group, c.String())
+	if item == nil {
+		message.Errorf("Shortcut is NOT found. 단축키를 확인해주세요.")
+		c.SetText("")
+		return
+	}
+	if m.remove {
+		m.key = item.Key
+		c.SetText("y")
+		return
+	}
+	c.Exit()
+	edited := *item
+	m.editBookmark(m.group, item.Key, &edited)
+}
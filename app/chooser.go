@@ -0,0 +1,47 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/epainos/gofuli/util"
+)
+
+// chooser is the file to write paths chosen by enter, or "-" for stdout.
+var chooser = struct {
+	path   string
+	chosen []string
+}{}
+
+// SetChooser makes enter choose marked files or the cursor file and quit,
+// and the paths are written to the file or "-" for stdout by WriteChosen.
+// Enter changes the directory if nothing is marked and the cursor is on it.
+func SetChooser(path string) { chooser.path = path }
+
+// IsChooser reports whether goful runs to choose files.
+func IsChooser() bool { return chooser.path != "" }
+
+func (g *Goful) choose() {
+	if !g.Dir().IsMark() && g.File().IsDir() {
+		g.Dir().EnterDir()
+		return
+	}
+	chooser.chosen = g.Dir().MarkfilePaths()
+	g.exit = true
+}
+
+// WriteChosen writes chosen paths a line each, and nothing if quitted
+// without choosing. It must be called after the screen is finished to
+// write to stdout.
+func WriteChosen() error {
+	if len(chooser.chosen) == 0 {
+		return nil
+	}
+	text := strings.Join(chooser.chosen, "\n") + "\n"
+	if chooser.path == "-" {
+		_, err := fmt.Print(text)
+		return err
+	}
+	return ioutil.WriteFile(util.ExpandPath(chooser.path), []byte(text), 0644)
+}
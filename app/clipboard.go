@@ -0,0 +1,110 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/epainos/gofuli/message"
+	"github.com/f1bonacc1/glippy"
+)
+
+// clipboard holds paths yanked or cut to paste in another directory.
+var clipboard = struct {
+	paths  []string
+	cut    bool
+	export bool // also sets paths to the system clipboard
+}{}
+
+// SetClipboardExport sets whether yanked paths are also set to the system
+// clipboard one path per line.
+func SetClipboardExport(export bool) { clipboard.export = export }
+
+// Yank puts marked files to the clipboard to copy by paste.
+func (g *Goful) Yank() { g.setClipboard(false) }
+
+// Cut puts marked files to the clipboard to move by paste.
+func (g *Goful) Cut() { g.setClipboard(true) }
+
+func (g *Goful) setClipboard(cut bool) {
+	paths := []string{}
+	for _, path := range g.Dir().MarkfilePaths() {
+		if filepath.Base(path) != ".." {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+	clipboard.paths = paths
+	clipboard.cut = cut
+	g.Dir().MarkClear()
+	if clipboard.export {
+		if err := glippy.Set(strings.Join(paths, "\n")); err != nil {
+			message.Error(err)
+		}
+	}
+	if cut {
+		message.Infof("Cut %d files(잘라냄)", len(paths))
+	} else {
+		message.Infof("Yanked %d files(복사함)", len(paths))
+	}
+}
+
+// Paste copies files in the clipboard to the directory, or moves if cut or
+// move is true. Paths copied by other applications one per line are pasted
+// if the clipboard is empty.
+func (g *Goful) Paste(move bool) {
+	if !writable() {
+		return
+	}
+	paths := clipboard.paths
+	if len(paths) == 0 {
+		text, _ := glippy.Get()
+		paths = parseClipboard(text)
+	}
+	if len(paths) == 0 {
+		message.Info("Clipboard is empty")
+		return
+	}
+	dst := g.Dir().Path
+	if move || clipboard.cut {
+		g.moveThen(func(err error) { removeClipboard(gonePaths(paths, err)) }, dst, paths...)
+	} else {
+		g.copy(dst, paths...)
+	}
+}
+
+// removeClipboard removes moved paths from the clipboard, and keeps paths
+// failed or skipped to paste again.
+func removeClipboard(gone []string) {
+	moved := map[string]bool{}
+	for _, path := range gone {
+		moved[path] = true
+	}
+	paths := []string{}
+	for _, path := range clipboard.paths {
+		if !moved[path] {
+			paths = append(paths, path)
+		}
+	}
+	clipboard.paths = paths
+	if len(paths) == 0 {
+		clipboard.cut = false
+	}
+}
+
+// parseClipboard returns paths in the text one per line such as copied by
+// "Copy as path" of the explorer or cmd+opt+c of the finder.
+func parseClipboard(text string) []string {
+	paths := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 1 && (line[0] == '"' || line[0] == '\'') && line[len(line)-1] == line[0] {
+			line = line[1 : len(line)-1]
+		}
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/epainos/gofuli/config"
+	"github.com/epainos/gofuli/filer"
+	"github.com/epainos/gofuli/look"
+	"github.com/epainos/gofuli/menu"
+	"github.com/epainos/gofuli/util"
+	"github.com/epainos/gofuli/widget"
+)
+
+// actions returns callbacks referenced by names in the configuration file.
+func (g *Goful) actions() map[string]func() {
+	return map[string]func(){
+		"copy":        func() { g.Copy() },
+		"move":        func() { g.Move() },
+		"remove":      func() { g.Remove() },
+		"rename":      func() { g.Rename() },
+		"bulk-rename": func() { g.BulkRename() },
+		"mkdir":       func() { g.Mkdir() },
+		"touch":       func() { g.Touch() },
+		"chmod":       func() { g.Chmod() },
+		"chdir":       func() { g.Chdir() },
+		"glob":        func() { g.Glob() },
+		"globdir":     func() { g.Globdir() },
+		"grep":        func() { g.Grep() },
+		"find":        func() { g.Find() },
+		"finder":      func() { g.Dir().Finder() },
+		"flatten":     func() { g.Flatten() },
+		"shell":       func() { g.Shell("") },
+		"quit":        func() { g.Quit() },
+		"quit-nocd":   func() { g.QuitNoCd() },
+		"yank":        func() { g.Yank() },
+		"cut":         func() { g.Cut() },
+		"paste":       func() { g.Paste(false) },
+		"paste-move":  func() { g.Paste(true) },
+		"jump":        func() { g.Jump() },
+		"visited":     func() { g.VisitedDir(false) },
+		"recent":      func() { g.VisitedDir(true) },
+		"sort-keys":   func() { g.SortKeys() },
+		"script":      func() { g.Script() },
+
+		"enter":         func() { g.Dir().EnterDir() },
+		"parent":        func() { g.Dir().Chdir("..") },
+		"back":          func() { g.Dir().GoPreviousFolder() },
+		"forward":       func() { g.Dir().GoFowardFolder() },
+		"reset":         func() { g.Dir().Reset() },
+		"reload":        func() { g.Workspace().ReloadAll() },
+		"cursor-down":   func() { g.Dir().MoveCursor(1) },
+		"cursor-up":     func() { g.Dir().MoveCursor(-1) },
+		"top":           func() { g.Dir().MoveTop() },
+		"bottom":        func() { g.Dir().MoveBottom() },
+		"page-down":     func() { g.Dir().PageDown() },
+		"page-up":       func() { g.Dir().PageUp() },
+		"mark":          func() { g.Dir().ToggleMark() },
+		"mark-invert":   func() { g.Dir().InvertMark() },
+		"mark-clear":    func() { g.Dir().MarkClear() },
+		"mark-restore":  func() { g.Dir().RestoreMarks() },
+		"mark-same-ext": func() { g.Dir().MarkSameExt() },
+		"visual":        func() { g.Dir().ToggleVisual() },
+		"tree":          func() { g.Dir().ToggleTree() },
+		"expand":        func() { g.Dir().ToggleExpand() },
+		"preview":       func() { g.Workspace().TogglePreview() },
+		"hidden":        func() { filer.ToggleShowHiddens(); g.Workspace().ReloadAll() },
+
+		"sort-name":    func() { g.Dir().SortName() },
+		"sort-size":    func() { g.Dir().SortSize() },
+		"sort-time":    func() { g.Dir().SortMtime() },
+		"sort-ext":     func() { g.Dir().SortExt() },
+		"sort-natural": func() { g.Dir().SortNatural() },
+
+		"tab-new":    func() { g.CreateWorkspace() },
+		"tab-close":  func() { g.CloseWorkspace() },
+		"tab-next":   func() { g.MoveWorkspace(1) },
+		"tab-prev":   func() { g.MoveWorkspace(-1) },
+		"pane-new":   func() { g.Workspace().CreateDir() },
+		"pane-close": func() { g.Workspace().CloseDir() },
+		"pane-next":  func() { g.Workspace().MoveFocus(1) },
+		"pane-prev":  func() { g.Workspace().MoveFocus(-1) },
+		"pane-swap":  func() { g.Workspace().SwapNextDir() },
+
+		"basket-add":  func() { g.BasketAdd() },
+		"basket-show": func() { g.Dir().ShowBasket() },
+		"git-stage":   func() { g.GitStage() },
+		"git-unstage": func() { g.GitUnstage() },
+		"git-diff":    func() { g.GitDiff(false) },
+	}
+}
+
+// action returns the callback of the action name, or of the template such
+// as "!cmd" spawning the command, "shell:cmd" editing the command in the
+// shell mode, "menu:name", "chdir:path" and "lua:name" of a script command.
+func (g *Goful) action(actions map[string]func(), action string) (func(), error) {
+	if strings.HasPrefix(action, "!") {
+		cmd := action[1:]
+		return func() { g.Spawn(cmd) }, nil
+	}
+	if i := strings.Index(action, ":"); i > 0 {
+		arg := action[i+1:]
+		switch action[:i] {
+		case "shell":
+			return func() { g.Shell(arg) }, nil
+		case "menu":
+			return func() { g.Menu(arg) }, nil
+		case "chdir":
+			return func() { g.Dir().Chdir(arg) }, nil
+		case "lua":
+			if _, ok := scripts.commands[arg]; !ok {
+				return nil, fmt.Errorf("unknown script command %q", arg)
+			}
+			return func() { g.RunScript(arg) }, nil
+		}
+	}
+	if callback, ok := actions[action]; ok {
+		return callback, nil
+	}
+	return nil, fmt.Errorf("unknown action %q", action)
+}
+
+// LoadConfig loads the configuration file overriding settings of the code.
+// Errors are reported with line numbers, and valid settings are applied.
+func (g *Goful) LoadConfig(path string) error {
+	actions := g.actions()
+	check := func(action string) error {
+		_, err := g.action(actions, action)
+		return err
+	}
+	c, err := config.Load(util.ExpandPath(path), check)
+	if c == nil {
+		return err
+	}
+	callback := func(action string) func() {
+		fn, _ := g.action(actions, action)
+		return fn
+	}
+	if c.Theme != "" {
+		look.Set(c.Theme)
+	}
+	if c.Stat != nil {
+		filer.SetStatView(c.Stat.Size, c.Stat.Permission, c.Stat.Time)
+	}
+	if c.Shell != nil {
+		g.ConfigShell(commandTemplate(c.Shell))
+	}
+	if c.Terminal != nil {
+		g.ConfigTerminal(commandTemplate(c.Terminal))
+	}
+	if c.Opener != "" {
+		opener := c.Opener
+		open := func() { g.Spawn(opener) }
+		g.MergeKeymap(widget.Keymap{"C-m": open, "o": open, "l": open, "right": open})
+	}
+	for _, m := range c.Menus {
+		for _, item := range m.Items {
+			if fn := callback(item.Action); fn != nil {
+				menu.Remove(m.Name, item.Key)
+				menu.Add(m.Name, item.Key, item.Label, fn)
+			}
+		}
+		if m.Key != "" {
+			name := m.Name
+			g.AddKeymap(m.Key, func() { g.Menu(name) })
+		}
+	}
+	for _, b := range c.Keymap {
+		if fn := callback(b.Action); fn != nil {
+			g.AddKeymap(b.Key, fn)
+		}
+	}
+	associate := map[string]func(){}
+	for _, b := range c.Extmap {
+		if fn := callback(b.Action); fn != nil {
+			associate[b.Key] = fn
+		}
+	}
+	if len(associate) > 0 {
+		g.MergeExtmap(widget.Extmap{"C-m": associate, "l": associate, "right": associate})
+	}
+	return err
+}
+
+// commandTemplate returns the function replacing %s in the template with the
+// command.
+func commandTemplate(template []string) func(cmd string) []string {
+	return func(cmd string) []string {
+		args := make([]string, len(template))
+		for i, arg := range template {
+			args[i] = strings.Replace(arg, "%s", cmd, -1)
+		}
+		return args
+	}
+}
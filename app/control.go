@@ -0,0 +1,127 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/epainos/gofuli/control"
+	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/util"
+)
+
+// controlSocket is the socket path of the remote control, and "" disables it.
+var controlSocket = "~/.goful/gofuli.sock"
+
+// SetControlSocket sets the socket path of the remote control, and "" not to
+// listen.
+func SetControlSocket(path string) { controlSocket = path }
+
+// ControlSocket returns the socket path to send remote control requests,
+// which is overridden by GOFUL_SOCKET.
+func ControlSocket() string {
+	if path := os.Getenv(control.Env); path != "" {
+		return path
+	}
+	return util.ExpandPath(controlSocket)
+}
+
+// listenControl listens the remote control socket, or a socket named by the
+// process id if another goful listens it, and sets GOFUL_SOCKET for child
+// processes. GOFUL_SOCKET inherited from a parent goful is not listened. It
+// returns nil if not listening.
+func (g *Goful) listenControl() *control.Server {
+	path := util.ExpandPath(controlSocket)
+	if path == "" {
+		return nil
+	}
+	s, err := control.Listen(path, g.control)
+	if err == control.ErrInUse {
+		ext := filepath.Ext(path)
+		path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), os.Getpid(), ext)
+		s, err = control.Listen(path, g.control)
+	}
+	if err != nil {
+		message.Errorf("Remote control %s: %v", path, err)
+		return nil
+	}
+	os.Setenv(control.Env, path)
+	return s
+}
+
+// control runs the request on the UI loop and replies the state after it.
+func (g *Goful) control(req *control.Request) *control.Response {
+	done := make(chan *control.Response, 1)
+	g.syncCallback(func() {
+		res := &control.Response{}
+		if err := g.controlCommand(req); err != nil {
+			res.Error = err.Error()
+		}
+		res.State = g.controlState()
+		done <- res
+	})
+	return <-done
+}
+
+func (g *Goful) controlCommand(req *control.Request) error {
+	switch req.Command {
+	case "chdir":
+		if len(req.Args) != 1 {
+			return fmt.Errorf("chdir needs a path")
+		}
+		g.Dir().Chdir(req.Args[0])
+	case "select":
+		if len(req.Args) == 0 {
+			return fmt.Errorf("select needs paths")
+		}
+		dir := filepath.Dir(req.Args[0])
+		if dir != g.Dir().Path {
+			g.Dir().Chdir(dir)
+		}
+		names := []string{}
+		for _, path := range req.Args {
+			if filepath.Dir(path) == dir {
+				names = append(names, filepath.Base(path))
+			}
+		}
+		g.Dir().Select(names...)
+	case "exec":
+		if len(req.Args) == 0 {
+			return fmt.Errorf("exec needs an action")
+		}
+		action := strings.Join(req.Args, " ")
+		actions := g.actions()
+		callback, err := g.action(actions, action)
+		if err != nil && strings.Contains(action, " ") {
+			// "menu sort" as "menu:sort"
+			callback, err = g.action(actions, strings.Replace(action, " ", ":", 1))
+		}
+		if err != nil {
+			return err
+		}
+		callback()
+	case "get-marked", "get-state":
+	default:
+		return fmt.Errorf("unknown command %q", req.Command)
+	}
+	return nil
+}
+
+func (g *Goful) controlState() *control.State {
+	state := &control.State{Marked: []string{}}
+	w := g.Workspace()
+	for i, d := range w.Dirs {
+		state.Panes = append(state.Panes, control.Pane{Path: d.Path, Focus: i == w.Focus})
+	}
+	d := g.Dir()
+	if !d.IsEmpty() {
+		state.Cursor = d.File().Path()
+	}
+	if d.MarkCount() > 0 {
+		for _, f := range d.Markfiles() {
+			state.Marked = append(state.Marked, f.Path())
+		}
+	}
+	return state
+}
@@ -17,7 +17,25 @@ import (
 	"github.com/f1bonacc1/glippy"
 )
 
+// readOnly disables operations changing files.
+var readOnly = false
+
+// SetReadOnly disables file operations and the shell mode, which runs
+// commands changing files by keys. Spawning commands to open files works.
+func SetReadOnly(b bool) { readOnly = b }
+
+// writable reports whether files can be changed, showing the error if not.
+func writable() bool {
+	if readOnly {
+		message.Errorf("Read-only mode(읽기 전용)")
+	}
+	return !readOnly
+}
+
 func (g *Goful) rename(src, dst string) {
+	if !writable() {
+		return
+	}
 	if _, err := os.Lstat(dst); err != nil {
 		if !os.IsNotExist(err) {
 			message.Error(err)
@@ -40,6 +58,9 @@ func (g *Goful) rename(src, dst string) {
 }
 
 func (g *Goful) bulkRename(pattern, repl string, files ...*filer.FileStat) {
+	if !writable() {
+		return
+	}
 	re, err := regexp.Compile(pattern)
 	if err != nil {
 		message.Error(err)
@@ -87,6 +108,9 @@ func (g *Goful) bulkRename(pattern, repl string, files ...*filer.FileStat) {
 }
 
 func (g *Goful) chmod(mode os.FileMode, names ...string) {
+	if !writable() {
+		return
+	}
 	for _, name := range names {
 		if err := os.Chmod(name, mode); err != nil {
 			message.Error(err)
@@ -97,6 +121,9 @@ func (g *Goful) chmod(mode os.FileMode, names ...string) {
 }
 
 func (g *Goful) touch(name string, mode os.FileMode) {
+	if !writable() {
+		return
+	}
 	file, err := os.OpenFile(name, os.O_CREATE, mode)
 	if err != nil {
 		message.Error(err)
@@ -108,16 +135,29 @@ func (g *Goful) touch(name string, mode os.FileMode) {
 	message.Infof("Touched file %s", name)
 }
 
-func (g *Goful) remove(files ...string) {
+func (g *Goful) remove(files ...string) { g.removeThen(nil, files...) }
+
+// removeThen removes files in background and calls done with the error on
+// the main goroutine after removing if done is not nil.
+func (g *Goful) removeThen(done func(err error), files ...string) {
+	if !writable() {
+		return
+	}
 
 	filesAbs := make([]string, len(files))
 	for i := 0; i < len(files); i++ {
-		filesAbs[i], _ = filepath.Abs(files[i])
+		filesAbs[i] = absPath(files[i])
 	}
 	go func() {
-		defer g.syncCallback(func() { g.Workspace().ReloadAll() })
+		err := removeFiles(filesAbs...)
+		defer g.syncCallback(func() {
+			if done != nil {
+				done(err)
+			}
+			g.Workspace().ReloadAll()
+		})
 
-		if err := removeFiles(filesAbs...); err != nil {
+		if err != nil {
 			message.Error(err)
 		} else {
 			message.Infof("Removed %s", files)
@@ -127,12 +167,25 @@ func (g *Goful) remove(files ...string) {
 }
 
 func (g *Goful) copy(dst string, src ...string) {
+	if !writable() {
+		return
+	}
 	srcAbs := make([]string, len(src))
 	for i := 0; i < len(src); i++ {
-		srcAbs[i], _ = filepath.Abs(src[i])
+		srcAbs[i] = absPath(src[i])
 	}
-	dstAbs, _ := filepath.Abs(dst)
+	dstAbs := absPath(dst)
 
+	if isRemote(append(srcAbs, dstAbs)...) {
+		g.asyncFilectrl(func() {
+			if err := g.transferRemote(false, dstAbs, srcAbs...); err != nil {
+				message.Error(err)
+			} else {
+				message.Infof("Copied to %s from %s", dstAbs, srcAbs)
+			}
+		})
+		return
+	}
 	g.asyncFilectrl(func() {
 		walker := g.newWalker(overwriteNo, overwriteNo, copyJob{})
 		if err := letWalk(walker, dstAbs, srcAbs...); err != nil {
@@ -143,20 +196,36 @@ func (g *Goful) copy(dst string, src ...string) {
 	})
 }
 
-func (g *Goful) move(dst string, src ...string) {
+func (g *Goful) move(dst string, src ...string) { g.moveThen(nil, dst, src...) }
+
+// moveThen moves files in background and calls done with the error on the
+// main goroutine after moving if done is not nil.
+func (g *Goful) moveThen(done func(err error), dst string, src ...string) {
+	if !writable() {
+		return
+	}
 	srcAbs := make([]string, len(src))
 	for i := 0; i < len(src); i++ {
-		srcAbs[i], _ = filepath.Abs(src[i])
+		srcAbs[i] = absPath(src[i])
 	}
-	dstAbs, _ := filepath.Abs(dst)
+	dstAbs := absPath(dst)
 
 	g.asyncFilectrl(func() {
-		walker := g.newWalker(overwriteNo, overwriteNo, moveJob{})
-		if err := letWalk(walker, dstAbs, srcAbs...); err != nil {
+		var err error
+		if isRemote(append(srcAbs, dstAbs)...) {
+			err = g.transferRemote(true, dstAbs, srcAbs...)
+		} else {
+			walker := g.newWalker(overwriteNo, overwriteNo, moveJob{})
+			err = letWalk(walker, dstAbs, srcAbs...)
+		}
+		if err != nil {
 			message.Error(err)
 		} else {
 			message.Infof("Moved to %s from %s", dstAbs, srcAbs)
 		}
+		if done != nil {
+			g.syncCallback(func() { done(err) })
+		}
 	})
 }
 
@@ -176,6 +245,22 @@ func (g *Goful) asyncFilectrl(fn func()) {
 	}()
 }
 
+// gonePaths returns the paths not existing after moving or removing them,
+// where remote paths are gone unless the operation failed.
+func gonePaths(paths []string, err error) []string {
+	gone := []string{}
+	for _, path := range paths {
+		if isRemote(path) {
+			if err == nil {
+				gone = append(gone, path)
+			}
+		} else if _, e := os.Lstat(path); os.IsNotExist(e) {
+			gone = append(gone, path)
+		}
+	}
+	return gone
+}
+
 func letWalk(walker *walker, dst string, src ...string) error {
 	size, count := util.CalcSizeCount(src...)
 	progress.Start(float64(size))
@@ -427,6 +512,12 @@ func (job moveJob) afterVisitDir(src, dst string) error {
 
 func removeFiles(files ...string) error {
 	for _, file := range files {
+		if isRemote(file) {
+			if err := removeRemote(file); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := os.RemoveAll(file); err != nil {
 			return err
 		}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/epainos/gofuli/filer"
+	"github.com/epainos/gofuli/message"
+)
+
+// GitStage stages marked files by git add.
+func (g *Goful) GitStage() { g.git(g.gitTargets(), "add", "--") }
+
+// GitUnstage unstages marked files by git restore --staged.
+func (g *Goful) GitUnstage() { g.git(g.gitTargets(), "restore", "--staged", "--") }
+
+// GitRestore discards changes of marked files in the working tree after
+// confirming.
+func (g *Goful) GitRestore() {
+	if !writable() {
+		return
+	}
+	files := g.gitTargets()
+	if len(files) == 0 {
+		return
+	}
+	msg := fmt.Sprintf("Discard changes of %d files? %s", len(files), gitNames(files))
+	switch g.dialog(msg, "y", "n") {
+	case "y":
+		g.git(files, "restore", "--")
+	}
+}
+
+// GitDiff shows differences of marked files, or staged differences if cached.
+func (g *Goful) GitDiff(cached bool) {
+	if isRemote(g.Dir().Path) {
+		message.Errorf("Git is not supported in %s", g.Dir().Path)
+		return
+	}
+	if cached {
+		g.Spawn("git diff --cached -- %M")
+	} else {
+		g.Spawn("git diff -- %M")
+	}
+}
+
+// gitTargets returns marked files or the cursor file except "..", and
+// reports an error if nothing is selected.
+func (g *Goful) gitTargets() []*filer.FileStat {
+	files := []*filer.FileStat{}
+	for _, f := range g.Dir().Markfiles() {
+		if f.Name() != ".." {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		message.Errorf("No files selected(선택된 파일 없음)")
+	}
+	return files
+}
+
+// gitNames returns names of the first files, where directories end with a
+// slash to tell changes under them are included.
+func gitNames(files []*filer.FileStat) string {
+	names := []string{}
+	for i, f := range files {
+		if i == 3 {
+			names = append(names, "…")
+			break
+		}
+		if f.IsDir() {
+			names = append(names, f.Name()+"/")
+		} else {
+			names = append(names, f.Name())
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// git runs the git command with paths of the files in the directory, and
+// reloads to refresh the git status.
+func (g *Goful) git(files []*filer.FileStat, args ...string) {
+	if len(files) == 0 || !writable() {
+		return
+	}
+	if isRemote(g.Dir().Path) {
+		message.Errorf("Git is not supported in %s", g.Dir().Path)
+		return
+	}
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path()
+	}
+	cmd := exec.Command("git", append(args, paths...)...)
+	cmd.Dir = g.Dir().Path
+	if out, err := cmd.CombinedOutput(); err != nil {
+		message.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+	} else {
+		message.Infof("git %s %d files", strings.Join(args[:len(args)-1], " "), len(paths))
+	}
+	g.Dir().MarkClear()
+	g.Workspace().ReloadAll()
+}
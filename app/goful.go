@@ -2,11 +2,15 @@
 package app
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/epainos/gofuli/filer"
 	"github.com/epainos/gofuli/info"
 	"github.com/epainos/gofuli/menu"
 	"github.com/epainos/gofuli/message"
 	"github.com/epainos/gofuli/progress"
+	"github.com/epainos/gofuli/util"
 	"github.com/epainos/gofuli/widget"
 	"github.com/gdamore/tcell/v2"
 )
@@ -21,6 +25,7 @@ type Goful struct {
 	interrupt chan int
 	callback  chan func()
 	task      chan int
+	wakeup    chan bool
 	exit      bool
 }
 
@@ -39,11 +44,44 @@ func NewGoful(path string) *Goful {
 		interrupt: make(chan int, 2),
 		callback:  make(chan func()),
 		task:      make(chan int, 1),
+		wakeup:    make(chan bool, 1),
 		exit:      false,
 	}
+	filer.ConfigWakeup(func() {
+		select {
+		case goful.wakeup <- true:
+		default:
+		}
+	})
 	return goful
 }
 
+// OpenPanes changes the left and the right panes of the workspace to the
+// paths, adding the right pane if not exists. Empty paths are not changed,
+// and a file path sets the cursor to the file.
+func (g *Goful) OpenPanes(left, right string) {
+	w := g.Workspace()
+	if right != "" && len(w.Dirs) < 2 {
+		w.CreateDir()
+		w.Dirs[0].Chdir(w.Dirs[1].Path)
+	}
+	for i, path := range []string{left, right} {
+		if path == "" {
+			continue
+		}
+		path = util.ExpandPath(path)
+		if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+			w.Dirs[i].Chdir(filepath.Dir(path))
+			w.Dirs[i].Select(filepath.Base(path))
+		} else {
+			w.Dirs[i].Chdir(path)
+		}
+	}
+	if left != "" || right != "" {
+		w.SetFocus(0)
+	}
+}
+
 // ConfigShell sets a function that returns a shell name and options.
 func (g *Goful) ConfigShell(config func(cmd string) []string) {
 	g.shell = config
@@ -91,6 +129,8 @@ func (g *Goful) Draw() {
 func (g *Goful) Input(key string) {
 	if !widget.IsNil(g.Next()) {
 		g.Next().Input(key)
+	} else if chooser.path != "" && key == "C-m" {
+		g.choose()
 	} else {
 		g.Filer.Input(key)
 	}
@@ -111,6 +151,14 @@ func (g *Goful) Run() {
 	message.Info("Welcome to goful")
 	g.Workspace().ReloadAll()
 
+	watcher := filer.NewWatcher(func(changes filer.Changes) {
+		g.syncCallback(func() { g.Refresh(changes) })
+	})
+	defer watcher.Close()
+	if s := g.listenControl(); s != nil {
+		defer s.Close()
+	}
+
 	go func() {
 		for {
 			g.event <- widget.PollEvent()
@@ -118,6 +166,7 @@ func (g *Goful) Run() {
 	}()
 
 	for !g.exit {
+		watcher.Watch(g.WatchPaths()...)
 		g.Draw()
 		widget.Show()
 		select {
@@ -127,6 +176,7 @@ func (g *Goful) Run() {
 			<-g.interrupt
 		case callback := <-g.callback:
 			callback()
+		case <-g.wakeup:
 		}
 	}
 }
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/epainos/gofuli/cmdline"
+	"github.com/epainos/gofuli/filer"
+	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/util"
+)
+
+// Jump starts the mode to change the directory to the most frecent visited
+// directory matching keywords.
+func (g *Goful) Jump() {
+	g.next = cmdline.New(&jumpMode{Goful: g}, g)
+}
+
+type jumpMode struct {
+	*Goful
+	candidates []string // ranked directories cycled by the completion
+	index      int
+}
+
+func (m *jumpMode) String() string { return "jump" }
+func (m *jumpMode) Prompt() string {
+	if len(m.candidates) > 1 {
+		return fmt.Sprintf("Jump(점프) [%d/%d]: ", m.index+1, len(m.candidates))
+	}
+	return "Jump(점프): "
+}
+func (m *jumpMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+
+// Complete sets the next candidate to the cmdline, or ranks candidates again
+// if keywords are edited.
+func (m *jumpMode) Complete(c *cmdline.Cmdline) {
+	if len(m.candidates) > 0 && c.String() == m.candidates[m.index] {
+		m.index = (m.index + 1) % len(m.candidates)
+	} else {
+		m.candidates = filer.JumpCandidates(strings.Fields(c.String()), m.Dir().Path)
+		m.index = 0
+		if len(m.candidates) == 0 {
+			message.Errorf("No directory matching %s", c.String())
+			return
+		}
+	}
+	c.SetText(m.candidates[m.index])
+}
+
+func (m *jumpMode) Run(c *cmdline.Cmdline) {
+	path := c.String()
+	if len(m.candidates) == 0 || path != m.candidates[m.index] {
+		candidates := filer.JumpCandidates(strings.Fields(path), m.Dir().Path)
+		if len(candidates) == 0 {
+			message.Errorf("No directory matching %s", path)
+			return
+		}
+		path = candidates[0]
+	}
+	m.Dir().Chdir(path)
+	c.Exit()
+}
+
+// JumpImport starts the mode to import the database of autojump, or of zoxide
+// by the name "zoxide".
+func (g *Goful) JumpImport() {
+	c := cmdline.New(&jumpImportMode{g}, g)
+	c.SetText("~/.local/share/autojump/autojump.txt")
+	g.next = c
+}
+
+type jumpImportMode struct {
+	*Goful
+}
+
+func (m *jumpImportMode) String() string          { return "jumpimport" }
+func (m *jumpImportMode) Prompt() string          { return "Import autojump file or zoxide(가져오기): " }
+func (m *jumpImportMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *jumpImportMode) Run(c *cmdline.Cmdline) {
+	name := c.String()
+	if name == "" {
+		return
+	}
+	var r io.Reader
+	if name == "zoxide" {
+		out, err := exec.Command("zoxide", "query", "--list", "--score").Output()
+		if err != nil {
+			message.Error(err)
+			return
+		}
+		r = bytes.NewReader(out)
+	} else {
+		file, err := os.Open(util.ExpandPath(name))
+		if err != nil {
+			message.Error(err)
+			return
+		}
+		defer file.Close()
+		r = file
+	}
+	n, err := filer.ImportFrecency(r)
+	if err != nil {
+		message.Error(err)
+		return
+	}
+	message.Infof("Imported %d directories from %s", n, name)
+	c.Exit()
+}
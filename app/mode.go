@@ -1,23 +1,21 @@
 package app
 
 import (
-	"bufio"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"strconv"
 	"strings"
 
 	// "github.com/epainos/gofuli/app" // Removed to fix import cycle and missing metadata issues
 	"github.com/epainos/gofuli/cmdline"
+	"github.com/epainos/gofuli/filer"
 	"github.com/epainos/gofuli/look"
-	"github.com/epainos/gofuli/menu"
 	"github.com/epainos/gofuli/message"
 	"github.com/epainos/gofuli/util"
 	"github.com/epainos/gofuli/widget"
-	"github.com/f1bonacc1/glippy"
 )
 
 // match shell separators, macros, options and spaces.
@@ -26,6 +24,9 @@ var re = regexp.MustCompile(`([;|>&])|(%~?(?:[&mMfFxX]|[dD]2?))|([[:space:]]-[[:
 // Shell starts the shell mode.
 // The head of variadic arguments is used for cursor positioning.
 func (g *Goful) Shell(cmd string, offset ...int) {
+	if !writable() {
+		return
+	}
 	commands, err := util.SearchCommands()
 	if err != nil {
 		message.Error(err)
@@ -41,6 +42,9 @@ func (g *Goful) Shell(cmd string, offset ...int) {
 // ShellSuspend starts the shell mode and suspends screen after running.
 // The head of variadic arguments is used for cursor positioning.
 func (g *Goful) ShellSuspend(cmd string, offset ...int) {
+	if !writable() {
+		return
+	}
 	commands, err := util.SearchCommands()
 	if err != nil {
 		message.Error(err)
@@ -155,23 +159,81 @@ func (m *dialogMode) Run(c *cmdline.Cmdline) {
 	c.SetText("")
 }
 
-// Quit starts the quit mode.
+// input reads a line in the prompt like dialog, and returns false if canceled.
+func (g *Goful) input(message, text string) (string, bool) {
+	g.interrupt <- 1
+	defer func() { g.interrupt <- 1 }()
+
+	tmp := g.Next()
+	input := &inputMode{message: message}
+	c := cmdline.New(input, g)
+	c.SetText(text)
+	g.next = c
+
+	for !widget.IsNil(g.Next()) {
+		g.Draw()
+		widget.Show()
+		g.eventHandler(<-g.event)
+	}
+	g.next = tmp
+	return input.result, input.ok
+}
+
+type inputMode struct {
+	message string
+	result  string
+	ok      bool
+}
+
+func (m *inputMode) String() string          { return "input" }
+func (m *inputMode) Prompt() string          { return m.message + " " }
+func (m *inputMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *inputMode) Run(c *cmdline.Cmdline) {
+	m.result, m.ok = c.String(), true
+	c.Exit()
+}
+
+// cdFile is the file to write the focused directory on quit for the shell
+// to change the directory.
+var cdFile = ""
+
+// SetCdFile sets the file to write the focused directory on quit, and ""
+// not to write.
+func SetCdFile(path string) { cdFile = path }
+
+// Quit starts the quit mode writing the focused directory to the cd file.
 func (g *Goful) Quit() {
-	g.next = cmdline.New(&quitMode{g}, g)
+	g.next = cmdline.New(&quitMode{g, true}, g)
+}
+
+// QuitNoCd starts the quit mode not writing the cd file.
+func (g *Goful) QuitNoCd() {
+	g.next = cmdline.New(&quitMode{g, false}, g)
 }
 
 type quitMode struct {
 	*Goful
+	cd bool
 }
 
-func (m quitMode) String() string          { return "quit" }
-func (m quitMode) Prompt() string          { return "Quit? 종료? [Y/n] " }
+func (m quitMode) String() string { return "quit" }
+func (m quitMode) Prompt() string {
+	if !m.cd && cdFile != "" {
+		return "Quit without cd? 이동 없이 종료? [Y/n] "
+	}
+	return "Quit? 종료? [Y/n] "
+}
 func (m quitMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
 func (m quitMode) Run(c *cmdline.Cmdline) {
 	switch c.String() {
 	case "Y", "y", "", "q":
 		c.Exit()
 		m.exit = true
+		if m.cd && cdFile != "" && !m.Dir().IsRemote() {
+			if err := ioutil.WriteFile(util.ExpandPath(cdFile), []byte(m.Dir().Path+"\n"), 0600); err != nil {
+				message.Error(err)
+			}
+		}
 	// case "n":
 	default:
 		c.Exit()
@@ -181,9 +243,14 @@ func (m quitMode) Run(c *cmdline.Cmdline) {
 
 // Copy starts the copy mode.
 func (g *Goful) Copy() {
+	if !writable() {
+		return
+	}
 	c := cmdline.New(&copyMode{g, ""}, g)
 	if g.Dir().IsMark() {
 		c.SetText(g.Workspace().NextDir().Path)
+	} else if g.Dir().IsRemote() {
+		c.SetText(g.File().Path())
 	} else {
 		c.SetText(g.File().Name())
 	}
@@ -225,9 +292,14 @@ func (m *copyMode) Run(c *cmdline.Cmdline) {
 
 // Move starts the move mode.
 func (g *Goful) Move() {
+	if !writable() {
+		return
+	}
 	c := cmdline.New(&moveMode{g, ""}, g)
 	if g.Dir().IsMark() {
 		c.SetText(g.Workspace().NextDir().Path)
+	} else if g.Dir().IsRemote() {
+		c.SetText(g.File().Path())
 	} else {
 		c.SetText(g.File().Name())
 	}
@@ -269,6 +341,9 @@ func (m *moveMode) Run(c *cmdline.Cmdline) {
 
 // Rename starts the rename mode.
 func (g *Goful) Rename() {
+	if !writable() {
+		return
+	}
 	src := g.File().Name()
 	c := cmdline.New(&renameMode{g, src}, g)
 	c.SetText(src)
@@ -298,6 +373,9 @@ func (m *renameMode) Run(c *cmdline.Cmdline) {
 
 // BulkRename starts the bulk rename mode.
 func (g *Goful) BulkRename() {
+	if !writable() {
+		return
+	}
 	g.next = cmdline.New(&bulkRenameMode{g, ""}, g)
 }
 
@@ -325,8 +403,13 @@ func (m *bulkRenameMode) Run(c *cmdline.Cmdline) {
 
 // Remove starts the remove mode.
 func (g *Goful) Remove() {
+	if !writable() {
+		return
+	}
 	c := cmdline.New(&removeMode{g, ""}, g)
-	if !g.Dir().IsMark() {
+	if g.Dir().IsRemote() && !g.Dir().IsMark() {
+		c.SetText(g.File().Path())
+	} else if !g.Dir().IsMark() {
 		c.SetText(g.File().Name())
 	}
 	g.next = c
@@ -371,6 +454,9 @@ func (m *removeMode) Run(c *cmdline.Cmdline) {
 
 // Mkdir starts the make directory mode.
 func (g *Goful) Mkdir() {
+	if !writable() {
+		return
+	}
 	g.next = cmdline.New(&mkdirMode{g, ""}, g)
 }
 
@@ -412,6 +498,9 @@ func (m *mkdirMode) Run(c *cmdline.Cmdline) {
 
 // Touch starts the touch file mode.
 func (g *Goful) Touch() {
+	if !writable() {
+		return
+	}
 	g.next = cmdline.New(&touchFileMode{g, ""}, g)
 }
 
@@ -450,6 +539,9 @@ func (m *touchFileMode) Run(c *cmdline.Cmdline) {
 
 // Chmod starts the change mode mode.
 func (g *Goful) Chmod() {
+	if !writable() {
+		return
+	}
 	c := cmdline.New(&chmodMode{g, nil}, g)
 	if !g.Dir().IsMark() {
 		c.SetText(g.File().Name())
@@ -540,6 +632,47 @@ func (m *chdirMode) Run(c *cmdline.Cmdline) {
 	}
 }
 
+// VisitedDir starts the mode to pick a visited directory of the pane, or of
+// all panes if global.
+func (g *Goful) VisitedDir(global bool) {
+	paths := g.Dir().VisitedPaths()
+	if global {
+		paths = filer.RecentPaths()
+	}
+	m := &visitedMode{g, global}
+	cmdline.SetHistory(m.String(), paths)
+	g.next = cmdline.New(m, g)
+}
+
+type visitedMode struct {
+	*Goful
+	global bool
+}
+
+func (m *visitedMode) String() string {
+	if m.global {
+		return "recentdir"
+	}
+	return "visiteddir"
+}
+func (m *visitedMode) Prompt() string {
+	if m.global {
+		return "Recent directory(최근 폴더): "
+	}
+	return "Visited directory(방문 폴더): "
+}
+func (m *visitedMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *visitedMode) Run(c *cmdline.Cmdline) {
+	path := c.String()
+	if h := c.History; h.Cursor() == h.Lower() && !h.IsEmpty() {
+		path = h.List()[0].Name() // the most recent path matching
+	}
+	if path != "" {
+		m.Dir().Chdir(path)
+		c.Exit()
+	}
+}
+
 // Glob starts the glob mode.
 func (g *Goful) Glob() {
 	g.next = cmdline.New(&globMode{g}, g)
@@ -578,381 +711,148 @@ func (m *globdirMode) Run(c *cmdline.Cmdline) {
 	}
 }
 
-//////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-
-func myfGetLastWord(filePath string) string {
-	lastIndex := strings.LastIndex(filePath, "/")
-
-	if lastIndex == -1 {
-		return filePath
-	}
-	return filePath[lastIndex+1:]
-}
-
-// addMyApp add my app added by user
-func (g *Goful) AddMyApp() {
-
-	src := ifElseSting((runtime.GOOS == "windows"), `start `, ifElseSting((runtime.GOOS == "darwin"), `open -a`, "")) + ` '` + g.File().Path() + `' ` + ` %F`
-	c := cmdline.New(&addMyAppMode{
-		Goful:              g,
-		myShortCut:         "",
-		myAppName:          "",
-		myAppCommand:       src,
-		isDoneMyAppCommand: false,
-	}, g)
-	c.SetText(src)
+// SortKeys starts the sort keys mode to sort by multiple keys.
+func (g *Goful) SortKeys() {
+	c := cmdline.New(&sortKeysMode{g}, g)
+	c.SetText(strings.ToLower(strings.NewReplacer("[^]", "", "[$]", " desc", ",", ", ").Replace(string(g.Dir().Sort))))
 	g.next = c
 }
 
-type addMyAppMode struct {
+type sortKeysMode struct {
 	*Goful
-	myShortCut         string
-	myAppName          string
-	myAppCommand       string
-	isDoneMyAppCommand bool
-}
-
-func (m *addMyAppMode) String() string { return "addMyApp" }
-func (m *addMyAppMode) Prompt() string {
-
-	if !m.isDoneMyAppCommand {
-		return "addMyApp 사용자앱 추가:"
-	} else if m.myAppName == "" {
-		return "appName 앱이름: "
-	} else {
-		return "shortCut for '" + m.myAppName + "' " + m.myShortCut + " 단축키: "
-	}
 }
 
-func (m *addMyAppMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
-func (m *addMyAppMode) Run(c *cmdline.Cmdline) {
-	if !m.isDoneMyAppCommand {
-		m.myAppCommand = c.String()
-		m.isDoneMyAppCommand = true
-		c.SetText(strings.ReplaceAll(myfGetLastWord(c.String()), `'`, ``))
-	} else if m.myAppName == "" {
-		m.myAppName = c.String()
-		c.SetText("")
-	} else {
-		m.myShortCut = c.String()
-		if len(m.myShortCut) == 1 {
-			writeMyAppToFile(myAppFile, m.myShortCut+" <||> "+m.myAppName+" <||> "+m.myAppCommand+"\n")
-			menu.Add("myApp", m.myShortCut, m.myAppName, func() { m.Spawn(m.myAppCommand) })
-			m.Workspace().ReloadAll()
-			c.Exit()
-		} else {
-			m.myShortCut = ": (type one character, please)"
-			c.SetText("")
-		}
-	}
+func (m *sortKeysMode) String() string { return "sortkeys" }
+func (m *sortKeysMode) Prompt() string {
+	return "Sort keys e.g. ext, size desc (정렬 키): "
 }
-
-const myAppFile = "~/.goful/myApp"
-
-func writeMyAppToFile(path string, content string) {
-
-	// file, err := os.Create(util.ExpandPath(path))
-	file, err := os.OpenFile(util.ExpandPath(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		glippy.Set("생성실패: " + err.Error())
-
-		return
-	}
-	defer file.Close()
-
-	// 파일 끝에 내용 추가
-	if _, err := file.WriteString(content); err != nil {
-		glippy.Set("쓰기실패: " + err.Error())
+func (m *sortKeysMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *sortKeysMode) Run(c *cmdline.Cmdline) {
+	if err := m.Dir().SortKeys(c.String()); err != nil {
+		message.Error(err)
 		return
 	}
-
+	c.Exit()
 }
 
-func (g *Goful) OpenMyAppList(path string) {
-	if path == "" {
-		path = "~/.goful/myApp"
-	}
-
-	file, err := os.OpenFile(util.ExpandPath(path), os.O_RDONLY, os.FileMode(0644))
-	if err != nil {
-		fmt.Println("파일 열기 실패:", err)
+// Flatten starts the flatten mode, or lists the directory as it is if flattened.
+func (g *Goful) Flatten() {
+	if g.Dir().IsFlatten() {
+		g.Dir().Unflatten()
 		return
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		items := strings.Split(line, " <||> ")
-		if len(items) == 3 {
-			menu.Add("myApp", items[0], items[1], func() { g.Spawn(items[2]) })
-			// fmt.Printf("항목1: %s, 항목2: %s, 항목3: %s\n", items[0], items[1], items[2])
-		} else {
-			// fmt.Println("잘못된 형식의 줄:", line)
-		}
-	}
-
-}
-
-// DelMyApp delete my app added by user
-func (g *Goful) DelMyApp() {
-	c := cmdline.New(&dellMyAppMode{
-		Goful:                g,
-		myShortCut:           "",
-		myShortCutAndAppName: make(map[string]string),
-		isInList:             false,
-	}, g)
-	c.SetText("")
-	g.next = c
+	g.next = cmdline.New(&flattenMode{g}, g)
 }
 
-type dellMyAppMode struct {
+type flattenMode struct {
 	*Goful
-	myShortCut           string
-	myShortCutAndAppName map[string]string
-	isInList             bool
-}
-
-func (m *dellMyAppMode) String() string { return "dellMyApp" }
-func (m *dellMyAppMode) Prompt() string {
-	shortcutList := []string{""}
-	shortcutList, m.myShortCutAndAppName = loadMyShortcuts(myAppFile)
-
-	if len(shortcutList) > 10 {
-		shortcutList = shortcutList[:10]
-		shortcutList[9] = "..."
-	}
-	src := strings.Join(shortcutList, ", ")
-	if m.myShortCut == "" {
-		return "App Shortcut to Delete (지울 단축키): " + src + " : "
-		// } else if m.myShortCutAndAppName[m.myShortCut] == "" {
-		// 	return "Shortcut is NOT found. 단축키를 확인해주세요."
-	} else {
-		return "del your app? '" + m.myShortCutAndAppName[m.myShortCut] + "' [Y, n] : "
-	}
 }
 
-// func (m *dellMyAppMode) Prompt() string          { return fmt.Sprintf("dellMyApp(이름변경) %s -> ", m.src) }
-func (m *dellMyAppMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
-func (m *dellMyAppMode) Run(c *cmdline.Cmdline) {
-	if m.myShortCutAndAppName[c.String()] != "" {
-		m.isInList = true
-	}
-	if len(m.myShortCutAndAppName) == 0 {
-		message.Info("No app to delete")
-		c.Exit()
-	} else if m.isInList == false && m.myShortCutAndAppName[c.String()] == "" {
-		message.Errorf("Shortcut is NOT found. 단축키를 확인해주세요.")
-		// m.myShortCut = c.String()
-		c.SetText("")
-	} else if m.myShortCut == "" {
-		m.myShortCut = c.String()
-		c.SetText("y")
-
-	} else {
-		if c.String() == "Y" || c.String() == "y" || c.String() == "" {
-
-			menu.DelMyAppFromListFile(myAppFile, m.myShortCut)
-			message.Info("Deleted: " + m.myShortCut + " (" + m.myShortCutAndAppName[m.myShortCut] + ") ")
-			menu.Remove("myApp", m.myShortCut)
-			// m.Workspace().ReloadAll()
-			// m.OpenMyAppList("")
-			c.Exit()
-			// }
-		} else {
-			message.Info("canceled: " + m.myShortCut + " (" + m.myShortCutAndAppName[m.myShortCut] + ") ")
-			c.Exit()
-		}
-	}
-}
-
-func loadMyShortcuts(path string) ([]string, map[string]string) {
-	var myShortCutList []string
-	mapForShortcutAndAppName := make(map[string]string)
-
-	file, err := os.OpenFile(util.ExpandPath(path), os.O_RDONLY, os.FileMode(0644))
-	if err != nil {
-		return nil, mapForShortcutAndAppName
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, " <||> ")
-		// println(parts[0], `:`, parts[1], `:`, parts[2])
-		if len(parts) == 3 {
-			myShortCutList = append(myShortCutList, parts[0])
-			key, value := parts[0], parts[1]
-			mapForShortcutAndAppName[key] = value
-		} else {
-			fmt.Println("잘못된 형식의 줄:", line)
+func (m *flattenMode) String() string          { return "flatten" }
+func (m *flattenMode) Prompt() string          { return "Flatten depth, empty is unlimited(펼침 깊이): " }
+func (m *flattenMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *flattenMode) Run(c *cmdline.Cmdline) {
+	depth := 0
+	if s := c.String(); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			message.Errorf("Invalid depth %s", s)
+			return
 		}
+		depth = n
 	}
-
-	if err := scanner.Err(); err != nil {
-		return myShortCutList, mapForShortcutAndAppName
-	}
-
-	return myShortCutList, mapForShortcutAndAppName
+	m.Dir().Flatten(depth)
+	c.Exit()
 }
 
-// //////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// addMyBookmark add my bookmart by user
-func (g *Goful) AddMyBookmark() {
-
-	src := strings.ReplaceAll(g.Dir().Path, "\\", "/")
-	c := cmdline.New(&addMyBookmarkMode{
-		Goful:                   g,
-		myShortCut:              "",
-		myBookmarkName:          "",
-		myBookmarkCommand:       src,
-		isDoneMyBookmarkCommand: false,
-	}, g)
-	c.SetText(src)
-	g.next = c
+// Grep starts the grep mode to search file contents under the directory.
+func (g *Goful) Grep() {
+	g.next = cmdline.New(&grepMode{g}, g)
 }
 
-type addMyBookmarkMode struct {
+type grepMode struct {
 	*Goful
-	myShortCut              string
-	myBookmarkName          string
-	myBookmarkCommand       string
-	isDoneMyBookmarkCommand bool
 }
 
-func (m *addMyBookmarkMode) String() string { return "addMyBookmark" }
-func (m *addMyBookmarkMode) Prompt() string {
-
-	if !m.isDoneMyBookmarkCommand {
-		return "add Bookmark      바로가기 추가:"
-	} else if m.myBookmarkName == "" {
-		return "nickname Bookmark 바로가기 별명: "
-	} else {
-		return "shortCut for '" + m.myBookmarkName + "' " + m.myShortCut + " 단축키: "
+func (m *grepMode) String() string          { return "grep" }
+func (m *grepMode) Prompt() string          { return "Grep regexp(내용검색): " }
+func (m *grepMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *grepMode) Run(c *cmdline.Cmdline) {
+	if c.String() == "" {
+		return
 	}
+	m.Dir().Grep(c.String())
+	c.Exit()
 }
 
-const myBookmarkFile = "~/.goful/myBookmark"
-
-// func (m *addMyBookmarkMode) Prompt() string          { return fmt.Sprintf("addMyBookmark(이름변경) %s -> ", m.src) }
-func (m *addMyBookmarkMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
-func (m *addMyBookmarkMode) Run(c *cmdline.Cmdline) {
-	if !m.isDoneMyBookmarkCommand {
-		m.myBookmarkCommand = c.String()
-		m.isDoneMyBookmarkCommand = true
-		c.SetText(myfGetLastWord(c.String()))
-	} else if m.myBookmarkName == "" {
-		m.myBookmarkName = c.String()
-		c.SetText("")
-	} else {
-		m.myShortCut = c.String()
-		if len(m.myShortCut) == 1 {
-			writeMyAppToFile(myBookmarkFile, m.myShortCut+" <||> "+m.myBookmarkName+" <||> "+m.myBookmarkCommand+"\n")
-			menu.Add("myBookmark", m.myShortCut, m.myBookmarkName, func() { m.Dir().Chdir(m.myBookmarkCommand) })
-			m.Workspace().ReloadAll()
-			c.Exit()
-		} else {
-			m.myShortCut = ": (type one character, please)"
-			c.SetText("")
-		}
-	}
+// Find starts the find mode to list files under the directory by a query.
+func (g *Goful) Find() {
+	g.next = cmdline.New(&findMode{g}, g)
 }
 
-func (g *Goful) OpenMyBookmarkList(path string) {
-	if path == "" {
-		path = "~/.goful/myBookmark"
-	}
+type findMode struct {
+	*Goful
+}
 
-	file, err := os.OpenFile(util.ExpandPath(path), os.O_RDONLY, os.FileMode(0644))
-	if err != nil {
-		fmt.Println("파일 열기 실패:", err)
+func (m *findMode) String() string { return "find" }
+func (m *findMode) Prompt() string {
+	return "Find query e.g. size>10M and mtime<7d (속성검색): "
+}
+func (m *findMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *findMode) Run(c *cmdline.Cmdline) {
+	if err := m.Dir().Find(c.String()); err != nil {
+		message.Error(err)
 		return
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		items := strings.Split(line, " <||> ")
-		if len(items) == 3 {
-			menu.Add("myBookmark", items[0], items[1], func() { g.Dir().Chdir(items[2]) })
-			// fmt.Printf("항목1: %s, 항목2: %s, 항목3: %s\n", items[0], items[1], items[2])
-		} else {
-			// fmt.Println("잘못된 형식의 줄:", line)
-		}
-	}
-
+	c.Exit()
 }
 
-// delMyBookmark delete my app added by user
-func (g *Goful) DelMyBookmark() {
-	c := cmdline.New(&delMyBookmarkMode{
-		Goful:                     g,
-		myShortCut:                "",
-		myShortCutAndBookmarkName: make(map[string]string),
-		isInList:                  false,
-	}, g)
-	c.SetText("")
+// Mark starts the mark mode to mark files by a glob pattern, a regexp or a
+// query of the find mode. Unmarks matched files if mark is false.
+func (g *Goful) Mark(kind, text string, mark bool) {
+	c := cmdline.New(&markMode{g, kind, mark}, g)
+	c.SetText(text)
 	g.next = c
 }
 
-type delMyBookmarkMode struct {
+type markMode struct {
 	*Goful
-	myShortCut                string
-	myShortCutAndBookmarkName map[string]string
-	isInList                  bool
+	kind string // glob, regexp or query
+	mark bool
 }
 
-func (m *delMyBookmarkMode) String() string { return "delMyBookmark" }
-func (m *delMyBookmarkMode) Prompt() string {
-	shortcutList := []string{""}
-	shortcutList, m.myShortCutAndBookmarkName = loadMyShortcuts(myBookmarkFile)
-
-	if len(shortcutList) > 10 {
-		shortcutList = shortcutList[:10]
-		shortcutList[9] = "..."
+func (m *markMode) String() string { return "mark" }
+func (m *markMode) Prompt() string {
+	if m.mark {
+		return fmt.Sprintf("Mark by %s(선택): ", m.kind)
 	}
-	src := strings.Join(shortcutList, ", ")
-	if m.myShortCut == "" {
-		return "Shortcut to Delete (지울 단축키): " + src + " : "
-		// } else if m.myShortCutAndAppName[m.myShortCut] == "" {
-		// 	return "Shortcut is NOT found. 단축키를 확인해주세요."
-	} else {
-		return "del your bookmark? '" + m.myShortCutAndBookmarkName[m.myShortCut] + "' [Y, n] : "
+	return fmt.Sprintf("Unmark by %s(선택 해제): ", m.kind)
+}
+func (m *markMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+func (m *markMode) Run(c *cmdline.Cmdline) {
+	var err error
+	switch m.kind {
+	case "glob":
+		err = m.Dir().MarkGlob(c.String(), m.mark)
+	case "regexp":
+		err = m.Dir().MarkRegexp(c.String(), m.mark)
+	default:
+		err = m.Dir().MarkQuery(c.String(), m.mark)
+	}
+	if err != nil {
+		message.Error(err)
+		return
 	}
+	c.Exit()
 }
 
-// func (m *delMyBookmarkMode) Prompt() string          { return fmt.Sprintf("delMyBookmark(이름변경) %s -> ", m.src) }
-func (m *delMyBookmarkMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
-func (m *delMyBookmarkMode) Run(c *cmdline.Cmdline) {
-	if m.myShortCutAndBookmarkName[c.String()] != "" {
-		m.isInList = true
-	}
-	if len(m.myShortCutAndBookmarkName) == 0 {
-		message.Info("No bookmark to delete...")
-		c.Exit()
-	} else if m.isInList == false && m.myShortCutAndBookmarkName[c.String()] == "" {
-		message.Errorf("Shortcut is NOT found. 단축키를 확인해주세요.")
-		// m.myShortCut = c.String()
-		c.SetText("")
-	} else if m.myShortCut == "" {
-		m.myShortCut = c.String()
-		c.SetText("y")
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-	} else {
-		if c.String() == "Y" || c.String() == "y" || c.String() == "" {
+func myfGetLastWord(filePath string) string {
+	lastIndex := strings.LastIndex(filePath, "/")
 
-			menu.DelMyAppFromListFile(myBookmarkFile, m.myShortCut)
-			message.Info("Deleted: " + m.myShortCut + " (" + m.myShortCutAndBookmarkName[m.myShortCut] + ") ")
-			menu.Remove("myBookmark", m.myShortCut)
-			// m.Workspace().ReloadAll()
-			// m.OpenMyAppList("")
-			c.Exit()
-			// }
-		} else {
-			message.Info("canceled: " + m.myShortCut + " (" + m.myShortCutAndBookmarkName[m.myShortCut] + ") ")
-			c.Exit()
-		}
+	if lastIndex == -1 {
+		return filePath
 	}
+	return filePath[lastIndex+1:]
 }
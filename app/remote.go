@@ -0,0 +1,358 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/epainos/gofuli/progress"
+	"github.com/epainos/gofuli/s3"
+	"github.com/epainos/gofuli/widget"
+)
+
+// absPath returns an absolute local path or an object storage path as it is.
+func absPath(name string) string {
+	if s3.IsPath(name) {
+		return name
+	}
+	abs, _ := filepath.Abs(name)
+	return abs
+}
+
+// isRemote reports whether any paths are on object storage.
+func isRemote(paths ...string) bool {
+	for _, p := range paths {
+		if s3.IsPath(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// transfer is a file to copy between local and object storage.
+type transfer struct {
+	src  string
+	dst  string
+	info os.FileInfo
+}
+
+// transferRemote copies or moves files between the local file system and
+// object storage with progressing. The sources are removed after copying if
+// move is true.
+func (g *Goful) transferRemote(move bool, dst string, src ...string) error {
+	client, err := s3.Default()
+	if err != nil {
+		return err
+	}
+	var transfers []transfer
+	for _, s := range src {
+		var ts []transfer
+		switch {
+		case s3.IsPath(s) && s3.IsPath(dst):
+			return fmt.Errorf("copy between object storage is not supported: %s -> %s", s, dst)
+		case s3.IsPath(dst):
+			ts, err = uploadPlan(dst, s)
+		default:
+			ts, err = downloadPlan(client, dst, s)
+		}
+		if err != nil {
+			return err
+		}
+		transfers = append(transfers, ts...)
+	}
+
+	size := int64(0)
+	for _, t := range transfers {
+		size += t.info.Size()
+	}
+	progress.Start(float64(size))
+	progress.StartTaskCount(len(transfers))
+	defer progress.Finish()
+	quit := drawProgress()
+	defer close(quit)
+
+	w := g.newWalker(overwriteNo, overwriteNo, nil)
+	for _, t := range transfers {
+		if transferExists(client, t) {
+			if w.fileConfirmed != overwriteYesAll {
+				if w.fileConfirmed == overwriteNoAll {
+					continue
+				}
+				w.fileConfirmed = w.confirm(fmt.Sprintf("Overwrite? %s", t.info.Name()))
+				switch w.fileConfirmed {
+				case overwriteNo, overwriteNoAll:
+					continue
+				case overwriteCancel:
+					return fmt.Errorf("canceled file operation")
+				case backup:
+					t.dst = backupName(t.dst, func(name string) bool {
+						return transferExists(client, transfer{dst: name})
+					})
+				}
+			}
+		}
+		progress.StartTask(t.info)
+		if s3.IsPath(t.dst) {
+			err = upload(client, t)
+		} else {
+			err = download(client, t)
+		}
+		progress.FinishTask()
+		if err != nil {
+			return err
+		}
+		if move {
+			if err := removePath(client, t.src); err != nil {
+				return err
+			}
+		}
+	}
+	if move {
+		for _, s := range src {
+			if !s3.IsPath(s) {
+				removeEmptyDirs(s)
+			}
+		}
+	}
+	return nil
+}
+
+// removeEmptyDirs removes directories left empty by moving from the deepest.
+func removeEmptyDirs(root string) {
+	var dirs []string
+	_ = filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err == nil && fi.IsDir() {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i]) // fails if not empty
+	}
+}
+
+// backupName returns a name not existing by appending underscores before the
+// extension like the backup of the local copy.
+func backupName(name string, exists func(string) bool) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for {
+		base += "_"
+		if !exists(base + ext) {
+			return base + ext
+		}
+	}
+}
+
+func transferExists(client *s3.Client, t transfer) bool {
+	if s3.IsPath(t.dst) {
+		_, err := client.Head(s3.Split(t.dst))
+		return err == nil
+	}
+	_, err := os.Lstat(t.dst)
+	return err == nil
+}
+
+// uploadPlan lists local files of the source into a prefix of a bucket.
+func uploadPlan(dst, src string) ([]transfer, error) {
+	bucket, prefix := s3.Split(dst)
+	if bucket == "" {
+		return nil, fmt.Errorf("upload needs a bucket: %s", dst)
+	}
+	var transfers []transfer
+	base := filepath.Dir(src)
+	err := filepath.Walk(src, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		key := path.Join(prefix, filepath.ToSlash(rel))
+		transfers = append(transfers, transfer{p, s3.Scheme + bucket + "/" + key, fi})
+		return nil
+	})
+	return transfers, err
+}
+
+// downloadPlan lists objects of the source as an object or a prefix into a
+// local directory.
+func downloadPlan(client *s3.Client, dst, src string) ([]transfer, error) {
+	bucket, key := s3.Split(src)
+	if fi, err := client.Head(bucket, key); err == nil {
+		name, err := localPath(dst, path.Base(key))
+		if err != nil {
+			return nil, err
+		}
+		return []transfer{{src, name, fi}}, nil
+	} else if !s3.IsNotExist(err) {
+		return nil, err
+	}
+
+	var transfers []transfer
+	base := ""
+	if i := strings.LastIndexByte(key, '/'); i >= 0 {
+		base = key[:i+1]
+	}
+	var keyErr error
+	err := client.List(bucket, key+"/", "", func(fi *s3.ObjectInfo) {
+		if strings.HasSuffix(fi.Key, "/") || keyErr != nil {
+			return
+		}
+		name, err := localPath(dst, strings.TrimPrefix(fi.Key, base))
+		if err != nil {
+			keyErr = err
+			return
+		}
+		transfers = append(transfers, transfer{s3.Scheme + bucket + "/" + fi.Key, name, fi})
+	})
+	if err == nil {
+		err = keyErr
+	}
+	if err == nil && len(transfers) == 0 {
+		return nil, fmt.Errorf("not found %s", src)
+	}
+	return transfers, err
+}
+
+// localPath joins the slash separated path of an object key to the local
+// directory. It fails if the key points outside of the directory.
+func localPath(dir, rel string) (string, error) {
+	clean := path.Clean(rel)
+	if path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("unsafe object key %s", rel)
+	}
+	name := filepath.Join(dir, filepath.FromSlash(clean))
+	prefix := filepath.Clean(dir)
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	if !strings.HasPrefix(name, prefix) {
+		return "", fmt.Errorf("unsafe object key %s", rel)
+	}
+	return name, nil
+}
+
+func upload(client *s3.Client, t transfer) error {
+	file, err := os.Open(t.src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	bucket, key := s3.Split(t.dst)
+	return client.Upload(bucket, key, file, t.info.Size(), func(n int) { progress.Update(float64(n)) })
+}
+
+func download(client *s3.Client, t transfer) error {
+	body, err := client.Get(s3.Split(t.src))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := os.MkdirAll(filepath.Dir(t.dst), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file to keep the existing file on failure
+	file, err := ioutil.TempFile(filepath.Dir(t.dst), "."+filepath.Base(t.dst)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := file.Name()
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(t.dst); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	_, err = io.Copy(file, progressReader{body})
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, mode)
+	}
+	if err == nil {
+		err = os.Chtimes(tmp, t.info.ModTime(), t.info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(tmp, t.dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+type progressReader struct {
+	io.Reader
+}
+
+func (r progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	progress.Update(float64(n))
+	return n, err
+}
+
+// drawProgress draws the progress periodically until the returned channel is closed.
+func drawProgress() chan bool {
+	quit := make(chan bool)
+	go func() {
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				progress.Draw()
+				widget.Show()
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return quit
+}
+
+// removePath removes a local file or an object.
+func removePath(client *s3.Client, name string) error {
+	if !s3.IsPath(name) {
+		return os.Remove(name)
+	}
+	return client.Delete(s3.Split(name))
+}
+
+// removeRemote removes an object or all objects having the prefix.
+func removeRemote(name string) error {
+	client, err := s3.Default()
+	if err != nil {
+		return err
+	}
+	bucket, key := s3.Split(name)
+	if key == "" {
+		return fmt.Errorf("cannot remove a bucket %s", name)
+	}
+	if _, err := client.Head(bucket, key); err == nil {
+		return client.Delete(bucket, key)
+	} else if !s3.IsNotExist(err) {
+		return err
+	}
+	var keys []string
+	if err := client.List(bucket, key+"/", "", func(fi *s3.ObjectInfo) {
+		keys = append(keys, fi.Key)
+	}); err != nil {
+		return err
+	}
+	keys = append(keys, key+"/") // the directory marker if exists
+	for _, k := range keys {
+		if err := client.Delete(bucket, k); err != nil && !s3.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
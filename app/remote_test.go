@@ -0,0 +1,49 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalPath(t *testing.T) {
+	dir := filepath.FromSlash("/tmp/dst")
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"x.txt", filepath.FromSlash("/tmp/dst/x.txt")},
+		{"dir/x.txt", filepath.FromSlash("/tmp/dst/dir/x.txt")},
+		{"dir/../x.txt", filepath.FromSlash("/tmp/dst/x.txt")},
+		{"..", ""},
+		{"dir/../../../.bashrc", ""},
+		{"../x.txt", ""},
+		{"/etc/passwd", ""},
+		{".", ""},
+	}
+	for _, tt := range tests {
+		got, err := localPath(dir, tt.key)
+		if tt.want == "" && err == nil {
+			t.Errorf("localPath(%q)=%q, want error", tt.key, got)
+		} else if tt.want != "" && got != tt.want {
+			t.Errorf("localPath(%q)=%q, want %q (%v)", tt.key, got, tt.want, err)
+		}
+	}
+}
+
+func TestBackupName(t *testing.T) {
+	exists := map[string]bool{"a.txt": true, "a_.txt": true, "b": true}
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"a.txt", "a__.txt"},
+		{"b", "b_"},
+		{"s3://bucket/dir/c.tar", "s3://bucket/dir/c_.tar"},
+	}
+	for _, tt := range tests {
+		got := backupName(tt.in, func(name string) bool { return exists[name] })
+		if got != tt.out {
+			t.Errorf("backupName(%q)=%q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
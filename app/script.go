@@ -0,0 +1,416 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epainos/gofuli/cmdline"
+	"github.com/epainos/gofuli/filer"
+	"github.com/epainos/gofuli/menu"
+	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/util"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// scripts is the Lua state of user scripts called on the UI goroutine.
+var scripts = struct {
+	state    *lua.LState
+	commands map[string]*lua.LFunction
+	timer    *scriptTimer // the timer of the running call
+	timeout  time.Duration
+}{
+	commands: map[string]*lua.LFunction{},
+	timeout:  2 * time.Second,
+}
+
+// SetScriptTimeout sets the time limit of a script call. The time waiting
+// for the user in dialogs is not counted.
+func SetScriptTimeout(d time.Duration) { scripts.timeout = d }
+
+// scriptTimer is the context canceled when a script runs over the timeout.
+type scriptTimer struct {
+	context.Context
+	cancel  context.CancelFunc
+	timer   *time.Timer
+	expired bool
+}
+
+func newScriptTimer() *scriptTimer {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &scriptTimer{Context: ctx, cancel: cancel}
+	t.timer = time.AfterFunc(scripts.timeout, t.cancel)
+	return t
+}
+
+func (t *scriptTimer) stop() {
+	t.expired = !t.timer.Stop()
+	t.cancel()
+}
+
+// LoadScripts runs *.lua files in the directory in name order. Scripts
+// register commands, keys and menu items by the goful module.
+func (g *Goful) LoadScripts(dir string) {
+	paths, err := filepath.Glob(filepath.Join(util.ExpandPath(dir), "*.lua"))
+	if err != nil || len(paths) == 0 {
+		return
+	}
+	if scripts.state == nil {
+		scripts.state = g.newScriptState()
+	}
+	for _, path := range paths {
+		fn, err := scripts.state.LoadFile(path)
+		if err != nil {
+			message.Error(err)
+			continue
+		}
+		if err := callScript(fn); err != nil {
+			message.Errorf("%s: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+// newScriptState creates the Lua state without libraries to access files
+// and processes, which scripts do through the goful module.
+func (g *Goful) newScriptState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "require"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	L.SetGlobal("print", L.NewFunction(func(L *lua.LState) int {
+		strs := make([]string, L.GetTop())
+		for i := range strs {
+			strs[i] = L.ToStringMeta(L.Get(i + 1)).String()
+		}
+		message.Info(strings.Join(strs, "\t"))
+		return 0
+	}))
+	L.SetGlobal("goful", L.SetFuncs(L.NewTable(), g.scriptFuncs()))
+	return L
+}
+
+// callScript calls the function within the timeout and returns the error
+// without the Lua traceback.
+func callScript(fn *lua.LFunction, args ...lua.LValue) error {
+	L := scripts.state
+	outer, timer := L.Context(), scripts.timer
+	scripts.timer = newScriptTimer()
+	L.SetContext(scripts.timer)
+	err := L.CallByParam(lua.P{Fn: fn, Protect: true}, args...)
+	scripts.timer.stop()
+	expired := scripts.timer.expired
+	scripts.timer = timer
+	if outer != nil {
+		L.SetContext(outer)
+	} else {
+		L.RemoveContext()
+	}
+	if expired && err != nil {
+		return fmt.Errorf("script timed out after %v", scripts.timeout)
+	} else if e, ok := err.(*lua.ApiError); ok {
+		return fmt.Errorf("%s", e.Object)
+	}
+	return err
+}
+
+// scriptCallback returns the callback calling the function and reporting
+// the error.
+func scriptCallback(fn *lua.LFunction) func() {
+	return func() {
+		if err := callScript(fn); err != nil {
+			message.Error(err)
+		}
+	}
+}
+
+// scriptWait stops the timer of the running call while waiting for the user.
+func scriptWait(wait func()) {
+	t := scripts.timer
+	if t == nil || !t.timer.Stop() {
+		wait()
+		return
+	}
+	wait()
+	t.timer.Reset(scripts.timeout)
+}
+
+// ScriptCommands returns names of commands registered by scripts.
+func ScriptCommands() []string {
+	names := make([]string, 0, len(scripts.commands))
+	for name := range scripts.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunScript calls the command registered by scripts.
+func (g *Goful) RunScript(name string) {
+	fn, ok := scripts.commands[name]
+	if !ok {
+		message.Errorf("Unknown script command %s", name)
+		return
+	}
+	scriptCallback(fn)()
+}
+
+// scriptFunction returns the function argument or the command of the name.
+func scriptFunction(L *lua.LState, n int) *lua.LFunction {
+	switch v := L.CheckAny(n).(type) {
+	case *lua.LFunction:
+		return v
+	case lua.LString:
+		if fn, ok := scripts.commands[string(v)]; ok {
+			return fn
+		}
+		L.ArgError(n, fmt.Sprintf("unknown command %q", string(v)))
+	default:
+		L.TypeError(n, lua.LTFunction)
+	}
+	return nil
+}
+
+// scriptPaths returns paths of arguments from n, which are strings, file
+// tables or arrays of them.
+func scriptPaths(L *lua.LState, n int) []string {
+	paths := []string{}
+	var add func(v lua.LValue)
+	add = func(v lua.LValue) {
+		switch v := v.(type) {
+		case lua.LString:
+			paths = append(paths, string(v))
+		case *lua.LTable:
+			if path, ok := v.RawGetString("path").(lua.LString); ok {
+				paths = append(paths, string(path))
+			} else {
+				v.ForEach(func(_, e lua.LValue) { add(e) })
+			}
+		}
+	}
+	for i := n; i <= L.GetTop(); i++ {
+		add(L.Get(i))
+	}
+	return paths
+}
+
+func fileTable(L *lua.LState, f *filer.FileStat) lua.LValue {
+	if f == nil {
+		return lua.LNil
+	}
+	t := L.NewTable()
+	t.RawSetString("name", lua.LString(f.Name()))
+	t.RawSetString("path", lua.LString(f.Path()))
+	t.RawSetString("ext", lua.LString(f.Ext()))
+	t.RawSetString("size", lua.LNumber(f.Size()))
+	t.RawSetString("mode", lua.LString(f.Mode().String()))
+	t.RawSetString("mtime", lua.LNumber(f.ModTime().Unix()))
+	t.RawSetString("isdir", lua.LBool(f.IsDir()))
+	t.RawSetString("islink", lua.LBool(f.IsLink()))
+	t.RawSetString("isexec", lua.LBool(!f.IsDir() && f.IsExec()))
+	t.RawSetString("marked", lua.LBool(f.IsMarked()))
+	return t
+}
+
+func filesTable(L *lua.LState, files []*filer.FileStat) *lua.LTable {
+	t := L.NewTable()
+	for _, f := range files {
+		if f != nil && f.Name() != ".." {
+			t.Append(fileTable(L, f))
+		}
+	}
+	return t
+}
+
+// scriptFuncs returns functions of the goful module.
+func (g *Goful) scriptFuncs() map[string]lua.LGFunction {
+	return map[string]lua.LGFunction{
+		"command": func(L *lua.LState) int {
+			scripts.commands[L.CheckString(1)] = L.CheckFunction(2)
+			return 0
+		},
+		"bind": func(L *lua.LState) int {
+			g.AddKeymap(L.CheckString(1), scriptCallback(scriptFunction(L, 2)))
+			return 0
+		},
+		"menu_add": func(L *lua.LState) int {
+			name, key, label := L.CheckString(1), L.CheckString(2), L.CheckString(3)
+			menu.Remove(name, key)
+			menu.Add(name, key, label, scriptCallback(scriptFunction(L, 4)))
+			return 0
+		},
+		"menu": func(L *lua.LState) int {
+			g.Menu(L.CheckString(1))
+			return 0
+		},
+		"info": func(L *lua.LState) int {
+			message.Info(L.CheckString(1))
+			return 0
+		},
+		"error": func(L *lua.LState) int {
+			message.Errorf("%s", L.CheckString(1))
+			return 0
+		},
+		"dir": func(L *lua.LState) int {
+			d := g.Dir()
+			t := L.NewTable()
+			t.RawSetString("path", lua.LString(d.Path))
+			t.RawSetString("base", lua.LString(d.Base()))
+			t.RawSetString("marks", lua.LNumber(d.MarkCount()))
+			L.Push(t)
+			return 1
+		},
+		"file": func(L *lua.LState) int {
+			L.Push(fileTable(L, g.File()))
+			return 1
+		},
+		"files": func(L *lua.LState) int {
+			files := []*filer.FileStat{}
+			for _, e := range g.Dir().List() {
+				files = append(files, e.(*filer.FileStat))
+			}
+			L.Push(filesTable(L, files))
+			return 1
+		},
+		"marked": func(L *lua.LState) int {
+			L.Push(filesTable(L, g.Dir().Markfiles()))
+			return 1
+		},
+		"mark": func(L *lua.LState) int {
+			name, on := L.CheckString(1), L.OptBool(2, true)
+			for _, e := range g.Dir().List() {
+				if f := e.(*filer.FileStat); f.Name() != name {
+					continue
+				} else if on {
+					f.Mark()
+				} else {
+					f.Markoff()
+				}
+			}
+			return 0
+		},
+		"cursor": func(L *lua.LState) int {
+			g.Dir().SetCursorByName(L.CheckString(1))
+			return 0
+		},
+		"chdir": func(L *lua.LState) int {
+			g.Dir().Chdir(L.CheckString(1))
+			return 0
+		},
+		"reload": func(L *lua.LState) int {
+			g.Workspace().ReloadAll()
+			return 0
+		},
+		"spawn": func(L *lua.LState) int {
+			g.Spawn(L.CheckString(1))
+			return 0
+		},
+		"shell": func(L *lua.LState) int {
+			g.Shell(L.OptString(1, ""))
+			return 0
+		},
+		"copy": func(L *lua.LState) int {
+			g.copy(L.CheckString(1), scriptPaths(L, 2)...)
+			return 0
+		},
+		"move": func(L *lua.LState) int {
+			g.move(L.CheckString(1), scriptPaths(L, 2)...)
+			return 0
+		},
+		"remove": func(L *lua.LState) int {
+			if paths := scriptPaths(L, 1); len(paths) > 0 {
+				g.remove(paths...)
+			}
+			return 0
+		},
+		"dialog": func(L *lua.LState) int {
+			msg := L.CheckString(1)
+			options := []string{}
+			for i := 2; i <= L.GetTop(); i++ {
+				options = append(options, L.CheckString(i))
+			}
+			if len(options) == 0 {
+				options = []string{"y", "n"}
+			}
+			var result string
+			scriptWait(func() { result = g.dialog(msg, options...) })
+			L.Push(lua.LString(result))
+			return 1
+		},
+		"input": func(L *lua.LState) int {
+			msg, text := L.CheckString(1), L.OptString(2, "")
+			var result string
+			var ok bool
+			scriptWait(func() { result, ok = g.input(msg, text) })
+			if ok {
+				L.Push(lua.LString(result))
+			} else {
+				L.Push(lua.LNil)
+			}
+			return 1
+		},
+	}
+}
+
+// Script starts the mode to run a command registered by scripts.
+func (g *Goful) Script() {
+	if len(scripts.commands) == 0 {
+		message.Info("No script commands")
+		return
+	}
+	g.next = cmdline.New(&scriptMode{Goful: g}, g)
+}
+
+type scriptMode struct {
+	*Goful
+	prefix string // the text completed by the names cycled
+}
+
+func (m *scriptMode) String() string          { return "script" }
+func (m *scriptMode) Prompt() string          { return "Script(스크립트): " }
+func (m *scriptMode) Draw(c *cmdline.Cmdline) { c.DrawLine() }
+
+// Complete sets the next command name starting with the typed text.
+func (m *scriptMode) Complete(c *cmdline.Cmdline) {
+	names := []string{}
+	text := c.String()
+	if _, ok := scripts.commands[text]; !ok || m.prefix == "" {
+		m.prefix = text
+	}
+	for _, name := range ScriptCommands() {
+		if strings.HasPrefix(name, m.prefix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		message.Errorf("No script command starting with %s", m.prefix)
+		return
+	}
+	next := names[0]
+	for i, name := range names {
+		if name == text && i+1 < len(names) {
+			next = names[i+1]
+		}
+	}
+	c.SetText(next)
+}
+
+func (m *scriptMode) Run(c *cmdline.Cmdline) {
+	c.Exit()
+	m.RunScript(c.String())
+}
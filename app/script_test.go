@@ -0,0 +1,51 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestCallScript(t *testing.T) {
+	scripts.state = (&Goful{}).newScriptState()
+	defer func() { scripts.state = nil }()
+	SetScriptTimeout(100 * time.Millisecond)
+	defer SetScriptTimeout(2 * time.Second)
+
+	for _, d := range []struct {
+		script string
+		err    string
+	}{
+		{`goful.command("hello", function() end)`, ""},
+		{`while true do end`, "script timed out after 100ms"},
+		{`dofile("/etc/passwd")`, `<string>:1: attempt to call a non-function object`},
+		{`os.exit(1)`, `<string>:1: attempt to index a non-table object(nil) with key 'exit'`},
+		{`error("failed")`, "<string>:1: failed"},
+	} {
+		fn, err := scripts.state.LoadString(d.script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = callScript(fn)
+		if got := errString(err); got != d.err {
+			t.Errorf("callScript(%q)=%q, want %q", d.script, got, d.err)
+		}
+	}
+	if _, ok := scripts.commands["hello"]; !ok {
+		t.Errorf("goful.command() did not register %q", "hello")
+	}
+	if scripts.state.Context() != nil {
+		t.Errorf("callScript() left the context")
+	}
+	if _, ok := scripts.state.GetGlobal("goful").(*lua.LTable); !ok {
+		t.Errorf("goful module is not a table")
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
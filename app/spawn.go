@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -93,6 +94,7 @@ const (
 	macroDir                   = 'd'  // %d %~d are expanded a directory name on the cursor
 	macroDirPath               = 'D'  // %D %~D are expanded a directory path on the cursor
 	macroNextDir               = '2'  // %d2 %D2 %~d2 %~D2 are expanded the neighbor directory name or path
+	macroGrepLine              = 'l'  // %l is expanded the first matched line number of the file listed by grep
 	macroTimestampDay          = 'T'  // 20241231
 	macroTimestampHour         = 't'  // 154501
 	macroRunBackground         = '&'  // %& is a flag runned in background
@@ -231,6 +233,8 @@ func (g *Goful) expandMacro(cmd string) (result string, background bool) {
 				}
 				src = ifElseSting(runtime.GOOS == "windows", strings.ReplaceAll(src, `\`, `/`), src)
 
+			case macroGrepLine:
+				src = strconv.Itoa(g.File().GrepLine())
 			case macroTimestampHour:
 				timestamp := time.Now().Format("150405")
 				src = timestamp
@@ -0,0 +1,271 @@
+// Package bookmark is the store of bookmarked directories and applications
+// grouped into menus.
+package bookmark
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Where to open bookmarked directories.
+const (
+	OpenHere = ""     // in the current pane
+	OpenTab  = "tab"  // in a new tab
+	OpenPane = "pane" // in a new pane of the tab
+)
+
+// Item is a bookmarked directory to change or an application to spawn.
+type Item struct {
+	Key         string `json:"key"` // one or more keys such as "d" or "gd"
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Command     string `json:"command,omitempty"`
+	Open        string `json:"open,omitempty"`
+}
+
+// Group is a named menu of items.
+type Group struct {
+	Name  string  `json:"name"`
+	Key   string  `json:"key,omitempty"` // opens the group from the bookmark menu
+	Items []*Item `json:"items"`
+}
+
+// Store is groups of items saved to the json file.
+type Store struct {
+	Groups []*Group `json:"groups"`
+}
+
+// Load reads the store from the json file. If the file does not exist, items
+// in old files of lines such as "key <||> name <||> path" are migrated into
+// groups named by the file base names, and the store is saved.
+func Load(path string, olds ...string) (*Store, error) {
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		s := &Store{}
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return s, s.validate()
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	s := &Store{}
+	migrated := false
+	for _, old := range olds {
+		file, err := os.Open(old)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		g := s.Group(filepath.Base(old))
+		g.Items, err = migrate(file, g.Name == "myApp")
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", old, err)
+		}
+		migrated = true
+	}
+	if migrated {
+		return s, s.Save(path)
+	}
+	return s, nil
+}
+
+// migrate parses lines of the old format skipping malformed and duplicate
+// lines, which are commands if app is true and paths otherwise.
+func migrate(r io.Reader, app bool) ([]*Item, error) {
+	items := []*Item{}
+	keys := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " <||> ", 3)
+		if len(fields) != 3 || fields[0] == "" || keys[fields[0]] {
+			continue
+		}
+		keys[fields[0]] = true
+		item := &Item{Key: fields[0], Name: fields[1]}
+		if app {
+			item.Command = fields[2]
+		} else {
+			item.Path = fields[2]
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// Save writes the store to the temporary file and renames it to the path not
+// to leave a broken file.
+func (s *Store) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Lookup returns the group of the name, or nil if not found.
+func (s *Store) Lookup(name string) *Group {
+	for _, g := range s.Groups {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// Clone returns a deep copy of the store.
+func (s *Store) Clone() *Store {
+	c := &Store{Groups: make([]*Group, len(s.Groups))}
+	for i, g := range s.Groups {
+		cg := *g
+		cg.Items = make([]*Item, len(g.Items))
+		for j, item := range g.Items {
+			ci := *item
+			cg.Items[j] = &ci
+		}
+		c.Groups[i] = &cg
+	}
+	return c
+}
+
+// Group returns the group of the name, created if not exists.
+func (s *Store) Group(name string) *Group {
+	if g := s.Lookup(name); g != nil {
+		return g
+	}
+	g := &Group{Name: name, Items: []*Item{}}
+	s.Groups = append(s.Groups, g)
+	return g
+}
+
+// Find returns the item of the key in the group, or nil if not found.
+func (s *Store) Find(group, key string) *Item {
+	for _, g := range s.Groups {
+		if g.Name != group {
+			continue
+		}
+		for _, item := range g.Items {
+			if item.Key == key {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// CheckKey returns an error if the key conflicts with keys in the group except
+// the old key.
+func (s *Store) CheckKey(group, old, key string) error {
+	for _, g := range s.Groups {
+		if g.Name != group {
+			continue
+		}
+		for _, item := range g.Items {
+			if item.Key == old && old != "" {
+				continue
+			}
+			if err := Conflict(item.Key, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Add adds the item to the group, or replaces the item of the old key if it
+// is not empty.
+func (s *Store) Add(group, old string, item *Item) error {
+	if err := item.validate(); err != nil {
+		return err
+	}
+	if err := s.CheckKey(group, old, item.Key); err != nil {
+		return err
+	}
+	g := s.Group(group)
+	if old == "" {
+		g.Items = append(g.Items, item)
+		return nil
+	}
+	for i, it := range g.Items {
+		if it.Key == old {
+			g.Items[i] = item
+			return nil
+		}
+	}
+	return fmt.Errorf("not found key `%s' in %s", old, group)
+}
+
+// Delete removes the item of the key from the group.
+func (s *Store) Delete(group, key string) error {
+	for _, g := range s.Groups {
+		if g.Name != group {
+			continue
+		}
+		for i, item := range g.Items {
+			if item.Key == key {
+				g.Items = append(g.Items[:i], g.Items[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("not found key `%s' in %s", key, group)
+}
+
+// Conflict returns an error if keys are the same or one is the prefix of the
+// other, which can not be typed.
+func Conflict(a, b string) error {
+	if strings.HasPrefix(a, b) || strings.HasPrefix(b, a) {
+		return fmt.Errorf("key `%s' conflicts with `%s'", b, a)
+	}
+	return nil
+}
+
+func (item *Item) validate() error {
+	switch {
+	case item.Key == "":
+		return fmt.Errorf("empty key of %s", item.Name)
+	case strings.ContainsAny(item.Key, " \t"):
+		return fmt.Errorf("key `%s' contains spaces", item.Key)
+	case (item.Path == "") == (item.Command == ""):
+		return fmt.Errorf("%s must have either path or command", item.Name)
+	}
+	switch item.Open {
+	case OpenHere, OpenTab, OpenPane:
+	default:
+		return fmt.Errorf("unknown open `%s' of %s", item.Open, item.Name)
+	}
+	return nil
+}
+
+func (s *Store) validate() error {
+	for _, g := range s.Groups {
+		for i, item := range g.Items {
+			if err := item.validate(); err != nil {
+				return fmt.Errorf("%s: %v", g.Name, err)
+			}
+			for _, other := range g.Items[:i] {
+				if err := Conflict(other.Key, item.Key); err != nil {
+					return fmt.Errorf("%s: %v", g.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package bookmark
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMigration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bookmark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	old := filepath.Join(dir, "myBookmark")
+	lines := "d <||> docs <||> /home/user/my docs\nbroken line\nd <||> dup <||> /tmp\nw <||> work <||> /a <||> b\n"
+	if err := ioutil.WriteFile(old, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "bookmarks.json")
+	s, err := Load(path, old, filepath.Join(dir, "myApp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []struct {
+		key, path string
+	}{
+		{"d", "/home/user/my docs"},
+		{"w", "/a <||> b"},
+	} {
+		if item := s.Find("myBookmark", d.key); item == nil || item.Path != d.path {
+			t.Errorf("Find(%q)=%v, want path %q", d.key, item, d.path)
+		}
+	}
+	if n := len(s.Group("myBookmark").Items); n != 2 {
+		t.Errorf("migrated %d items, want 2", n)
+	}
+
+	if err := s.Add("myBookmark", "", &Item{Key: "gd", Name: "go", Path: "/go", Open: OpenTab}); err != nil {
+		t.Fatal(err)
+	}
+	s2, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item := s2.Find("myBookmark", "w"); item == nil {
+		t.Errorf("Load() lost the migrated item")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	s := &Store{}
+	for _, d := range []struct {
+		old  string
+		item Item
+		ok   bool
+	}{
+		{"", Item{Key: "d", Name: "docs", Path: "/docs"}, true},
+		{"", Item{Key: "d", Name: "dup", Path: "/dup"}, false},
+		{"", Item{Key: "de", Name: "prefixed", Path: "/de"}, false},
+		{"", Item{Key: "gd", Name: "go", Path: "/go"}, true},
+		{"", Item{Key: "g", Name: "prefix", Path: "/g"}, false},
+		{"", Item{Key: "x", Name: "both", Path: "/x", Command: "x"}, false},
+		{"", Item{Key: "y", Name: "open", Path: "/y", Open: "window"}, false},
+		{"", Item{Key: "a b", Name: "space", Path: "/ab"}, false},
+		{"d", Item{Key: "de", Name: "docs", Path: "/docs"}, true},
+		{"z", Item{Key: "z", Name: "missing", Path: "/z"}, false},
+	} {
+		item := d.item
+		if err := s.Add("g", d.old, &item); (err == nil) != d.ok {
+			t.Errorf("Add(%q, %v)=%v, want ok %v", d.old, d.item, err, d.ok)
+		}
+	}
+	if err := s.Delete("g", "de"); err != nil {
+		t.Errorf("Delete(%q)=%v", "de", err)
+	}
+	if err := s.Delete("g", "de"); err == nil {
+		t.Errorf("Delete(%q) deleted twice", "de")
+	}
+}
+
+func TestClone(t *testing.T) {
+	s := &Store{}
+	if err := s.Add("g", "", &Item{Key: "d", Name: "docs", Path: "/docs"}); err != nil {
+		t.Fatal(err)
+	}
+	if g := s.Lookup("none"); g != nil || len(s.Groups) != 1 {
+		t.Errorf("Lookup(%q)=%v, groups %d, want nil, 1", "none", g, len(s.Groups))
+	}
+	c := s.Clone()
+	c.Find("g", "d").Name = "changed"
+	if err := c.Add("g", "", &Item{Key: "e", Name: "etc", Path: "/etc"}); err != nil {
+		t.Fatal(err)
+	}
+	if name := s.Find("g", "d").Name; name != "docs" {
+		t.Errorf("Clone() shares items, name %q, want %q", name, "docs")
+	}
+	if n := len(s.Lookup("g").Items); n != 1 {
+		t.Errorf("Clone() shares groups, %d items, want 1", n)
+	}
+}
@@ -23,6 +23,11 @@ type Mode interface {
 	Run(*Cmdline)
 }
 
+// Completer is a mode completing the cmdline text by itself instead of files.
+type Completer interface {
+	Complete(*Cmdline)
+}
+
 // Cmdline is one line text box with a specified mode.
 type Cmdline struct {
 	*widget.TextBox
@@ -72,6 +77,10 @@ func (c *Cmdline) Disconnect() { c.completion = widget.Nil() }
 
 // StartCompletion starts a completion based on the cmdline text.
 func (c *Cmdline) StartCompletion() {
+	if m, ok := c.mode.(Completer); ok {
+		m.Complete(c)
+		return
+	}
 	x, y := c.History.LeftTop()
 	width, height := c.History.Width(), c.History.Height()
 	comp := NewCompletion(x, y, width, height, c)
@@ -186,6 +195,11 @@ func LoadHistory(path string) error {
 	return nil
 }
 
+// SetHistory replaces the history of the mode name with the list from old to new.
+func SetHistory(name string, history []string) {
+	historyMap[name] = history
+}
+
 // SaveHistory saves the history to a path.
 func SaveHistory(path string) error {
 	path = util.ExpandPath(path)
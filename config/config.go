@@ -0,0 +1,419 @@
+// Package config loads the declarative configuration file of keymaps, menus,
+// extension associations and settings written in TOML.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// Binding is a key or an extension bound to an action.
+type Binding struct {
+	Line   int
+	Key    string
+	Action string
+}
+
+// MenuItem is an item of a menu.
+type MenuItem struct {
+	Line   int
+	Key    string
+	Label  string
+	Action string
+}
+
+// Menu is a menu added by menu.Add and opened by the key in the filer.
+type Menu struct {
+	Line  int
+	Name  string
+	Key   string
+	Items []MenuItem
+}
+
+// StatView is the default of the stat view.
+type StatView struct {
+	Size, Permission, Time bool
+}
+
+// Config is the configuration. Empty fields are not configured.
+type Config struct {
+	Theme    string
+	Opener   string   // the command template to open files
+	Shell    []string // the command and arguments, where %s is the command
+	Terminal []string
+	Stat     *StatView
+	Keymap   []Binding
+	Extmap   []Binding
+	Menus    []Menu
+}
+
+// Themes are names of looks.
+var Themes = []string{"default", "midnight", "black", "white", "original"}
+
+// Errors is errors of the configuration with line numbers.
+type Errors []string
+
+func (e Errors) Error() string { return strings.Join(e, "; ") }
+
+// Load loads the configuration file checking actions by the function. It
+// returns nil and no error if the file does not exist.
+func Load(path string, checkAction func(action string) error) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	c, errs := Decode(string(data), checkAction)
+	for i := range errs {
+		errs[i] = path + ":" + errs[i]
+	}
+	if len(errs) > 0 {
+		return c, errs
+	}
+	return c, nil
+}
+
+// Decode decodes the text and returns the configuration with all errors.
+func Decode(text string, checkAction func(action string) error) (*Config, Errors) {
+	root := map[string]interface{}{}
+	if err := toml.Unmarshal([]byte(text), &root); err != nil {
+		var de *toml.DecodeError
+		if errors.As(err, &de) {
+			line, _ := de.Position()
+			return nil, Errors{fmt.Sprintf("%d: %s", line, strings.TrimPrefix(de.Error(), "toml: "))}
+		}
+		msg := strings.TrimPrefix(err.Error(), "toml: ")
+		if line := redefinedLine([]byte(text)); line > 0 {
+			msg = fmt.Sprintf("%d: %s", line, msg)
+		}
+		return nil, Errors{msg}
+	}
+	d := &decoder{check: checkAction, positions: keyPositions([]byte(text))}
+	c := &Config{}
+	for _, key := range d.keys(root) {
+		v := root[key]
+		switch key {
+		case "theme":
+			c.Theme = d.str(v, key)
+			if c.Theme != "" && !contains(Themes, c.Theme) {
+				d.errorf(d.line(key), "unknown theme %q, want one of %s", c.Theme, strings.Join(Themes, ", "))
+			}
+		case "opener":
+			c.Opener = d.str(v, key)
+		case "shell":
+			c.Shell = d.command(v, key)
+		case "terminal":
+			c.Terminal = d.command(v, key)
+		case "stat":
+			c.Stat = d.stat(v)
+		case "keymap":
+			c.Keymap = d.bindings(v, key)
+		case "extmap":
+			c.Extmap = d.bindings(v, key)
+		case "menu":
+			if t := d.table(v, key); t != nil {
+				for _, name := range d.keys(t, key) {
+					c.Menus = append(c.Menus, d.menu(t[name], name))
+				}
+			}
+		default:
+			d.errorf(d.line(key), "unknown key %q", key)
+		}
+	}
+	sort.SliceStable(d.errs, func(i, j int) bool { return d.errs[i].line < d.errs[j].line })
+	var errs Errors
+	for _, e := range d.errs {
+		errs = append(errs, fmt.Sprintf("%d: %s", e.line, e.msg))
+	}
+	return c, errs
+}
+
+// position is the line number and the order of appearance of a key.
+type position struct {
+	line, order int
+}
+
+// keyPositions returns positions of keys and array elements in the text,
+// where the path of keys and indexes such as menu.tools.items.0 is joined by
+// null characters.
+func keyPositions(data []byte) map[string]position {
+	positions := map[string]position{}
+	walkKeys(data, func(path []string, line int, define bool) {
+		key := strings.Join(path, "\x00")
+		if _, ok := positions[key]; !ok {
+			positions[key] = position{line, len(positions)}
+		}
+	})
+	return positions
+}
+
+// redefinedLine returns the line number where a key or a table is defined
+// again, which is not reported by the decoder, or 0 if not found.
+func redefinedLine(data []byte) int {
+	defined := map[string]bool{}
+	found := 0
+	walkKeys(data, func(path []string, line int, define bool) {
+		key := strings.Join(path, "\x00")
+		if define && defined[key] && found == 0 {
+			found = line
+		}
+		defined[key] = defined[key] || define
+	})
+	return found
+}
+
+// walkKeys calls the function with paths of keys, tables and array elements
+// in the order of appearance, where define is true if the path is a value or
+// a table defined by the header.
+func walkKeys(data []byte, fn func(path []string, line int, define bool)) {
+	p := &unstable.Parser{}
+	p.Reset(data)
+	var lineOf func(n *unstable.Node) int
+	lineOf = func(n *unstable.Node) int {
+		if n.Raw.Length > 0 {
+			return p.Shape(n.Raw).Start.Line
+		}
+		for it := n.Children(); it.Next(); {
+			if line := lineOf(it.Node()); line > 0 {
+				return line
+			}
+		}
+		return 0
+	}
+	var keyValue func(table []string, n *unstable.Node)
+	var value func(path []string, n *unstable.Node, line int)
+	keyValue = func(table []string, n *unstable.Node) {
+		path := append([]string{}, table...)
+		line := 0
+		for it := n.Key(); it.Next(); {
+			path = append(path, string(it.Node().Data))
+			line = lineOf(it.Node())
+			fn(path, line, it.IsLast())
+		}
+		value(path, n.Value(), line)
+	}
+	value = func(path []string, n *unstable.Node, line int) {
+		switch n.Kind {
+		case unstable.Array:
+			i := 0
+			for it := n.Children(); it.Next(); i++ {
+				elem := child(path, strconv.Itoa(i))
+				l := lineOf(it.Node())
+				if l == 0 { // such as booleans without the position
+					l = line
+				}
+				fn(elem, l, false)
+				value(elem, it.Node(), l)
+			}
+		case unstable.InlineTable:
+			for it := n.Children(); it.Next(); {
+				keyValue(path, it.Node())
+			}
+		}
+	}
+	var table []string
+	for p.NextExpression() {
+		e := p.Expression()
+		switch e.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			table = nil
+			for it := e.Key(); it.Next(); {
+				table = append(table, string(it.Node().Data))
+				fn(table, lineOf(it.Node()), e.Kind == unstable.Table && it.IsLast())
+			}
+		case unstable.KeyValue:
+			keyValue(table, e)
+		}
+	}
+}
+
+type decoder struct {
+	check     func(string) error
+	positions map[string]position
+	errs      []lineError
+}
+
+type lineError struct {
+	line int
+	msg  string
+}
+
+func (d *decoder) errorf(line int, format string, a ...interface{}) {
+	d.errs = append(d.errs, lineError{line, fmt.Sprintf(format, a...)})
+}
+
+// line returns the line number of the path of keys and indexes.
+func (d *decoder) line(path ...string) int {
+	return d.positions[strings.Join(path, "\x00")].line
+}
+
+// keys returns keys of the table in the order of appearance.
+func (d *decoder) keys(t map[string]interface{}, path ...string) []string {
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	order := func(key string) int { return d.positions[strings.Join(child(path, key), "\x00")].order }
+	sort.Slice(keys, func(i, j int) bool { return order(keys[i]) < order(keys[j]) })
+	return keys
+}
+
+// child returns the path of the key in the table of the path.
+func child(path []string, key string) []string {
+	return append(append([]string{}, path...), key)
+}
+
+func (d *decoder) str(v interface{}, path ...string) string {
+	s, ok := v.(string)
+	if !ok {
+		d.errorf(d.line(path...), "%s must be a string", strings.Join(path, "."))
+	}
+	return s
+}
+
+func (d *decoder) table(v interface{}, path ...string) map[string]interface{} {
+	t, ok := v.(map[string]interface{})
+	if !ok {
+		d.errorf(d.line(path...), "%s must be a table", strings.Join(path, "."))
+	}
+	return t
+}
+
+func (d *decoder) strs(v interface{}, name string, path ...string) []string {
+	values, ok := v.([]interface{})
+	if !ok {
+		d.errorf(d.line(path...), "%s must be an array of strings", name)
+		return nil
+	}
+	strs := []string{}
+	for i, e := range values {
+		if s, ok := e.(string); ok {
+			strs = append(strs, s)
+		} else {
+			d.errorf(d.line(child(path, strconv.Itoa(i))...), "%s must be an array of strings", name)
+		}
+	}
+	return strs
+}
+
+func (d *decoder) command(v interface{}, key string) []string {
+	strs := d.strs(v, key, key)
+	if len(strs) == 0 {
+		d.errorf(d.line(key), "%s must have the command", key)
+		return nil
+	}
+	for _, s := range strs {
+		if strings.Contains(s, "%s") {
+			return strs
+		}
+	}
+	d.errorf(d.line(key), "%s must have %%s replaced by the command", key)
+	return nil
+}
+
+func (d *decoder) action(line int, action string) {
+	if err := d.check(action); err != nil {
+		d.errorf(line, "%v", err)
+	}
+}
+
+func (d *decoder) stat(v interface{}) *StatView {
+	t := d.table(v, "stat")
+	if t == nil {
+		return nil
+	}
+	stat := &StatView{Size: true}
+	for _, key := range d.keys(t, "stat") {
+		b, ok := t[key].(bool)
+		if !ok {
+			d.errorf(d.line("stat", key), "stat.%s must be a boolean", key)
+			continue
+		}
+		switch key {
+		case "size":
+			stat.Size = b
+		case "permission":
+			stat.Permission = b
+		case "time":
+			stat.Time = b
+		default:
+			d.errorf(d.line("stat", key), "unknown key stat.%s", key)
+		}
+	}
+	return stat
+}
+
+func (d *decoder) bindings(v interface{}, key string) []Binding {
+	t := d.table(v, key)
+	if t == nil {
+		return nil
+	}
+	bindings := []Binding{}
+	for _, k := range d.keys(t, key) {
+		action := d.str(t[k], key, k)
+		if action == "" {
+			continue
+		}
+		line := d.line(key, k)
+		d.action(line, action)
+		bindings = append(bindings, Binding{line, k, action})
+	}
+	return bindings
+}
+
+func (d *decoder) menu(v interface{}, name string) Menu {
+	m := Menu{Line: d.line("menu", name), Name: name}
+	t := d.table(v, "menu", name)
+	if t == nil {
+		return m
+	}
+	for _, key := range d.keys(t, "menu", name) {
+		switch key {
+		case "key":
+			m.Key = d.str(t[key], "menu", name, key)
+		case "items":
+			values, ok := t[key].([]interface{})
+			if !ok {
+				d.errorf(d.line("menu", name, key), "menu.%s.items must be an array", name)
+				continue
+			}
+			accels := map[string]bool{}
+			for i, item := range values {
+				path := []string{"menu", name, key, strconv.Itoa(i)}
+				line := d.line(path...)
+				strs := d.strs(item, "menu item", path...)
+				if len(strs) != 3 {
+					d.errorf(line, "menu item must be [key, label, action]")
+					continue
+				}
+				if accels[strs[0]] {
+					d.errorf(line, "duplicate key %q in menu %s", strs[0], name)
+				}
+				accels[strs[0]] = true
+				d.action(line, strs[2])
+				m.Items = append(m.Items, MenuItem{line, strs[0], strs[1], strs[2]})
+			}
+		default:
+			d.errorf(d.line("menu", name, key), "unknown key menu.%s.%s", name, key)
+		}
+	}
+	return m
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func checkAction(action string) error {
+	if action == "copy" || action == "quit" || strings.HasPrefix(action, "!") || strings.HasPrefix(action, "menu:") {
+		return nil
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
+
+func TestDecode(t *testing.T) {
+	text := `# goful
+theme = "midnight"
+opener = 'xdg-open %m %&'
+shell = ["bash", "-c", "%s"]
+
+[stat]
+size = true
+time = true
+
+[keymap]
+c = "copy"
+"M-x" = "!make \"all\""
+
+[extmap]
+".pdf" = "!zathura %f %&"
+
+[menu.tools]
+key = "T"
+items = [
+  ["m", "make", "!make"], # build
+  ["q", "quit", "quit"],
+]
+`
+	c, errs := Decode(text, checkAction)
+	if len(errs) > 0 {
+		t.Fatalf("Decode() errors %v", errs)
+	}
+	want := &Config{
+		Theme:  "midnight",
+		Opener: "xdg-open %m %&",
+		Shell:  []string{"bash", "-c", "%s"},
+		Stat:   &StatView{Size: true, Time: true},
+		Keymap: []Binding{{11, "c", "copy"}, {12, "M-x", `!make "all"`}},
+		Extmap: []Binding{{15, ".pdf", "!zathura %f %&"}},
+		Menus: []Menu{{17, "tools", "T", []MenuItem{
+			{20, "m", "make", "!make"},
+			{21, "q", "quit", "quit"},
+		}}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Decode()=%+v, want %+v", c, want)
+	}
+}
+
+func TestDecodeInline(t *testing.T) {
+	text := `stat = { size = false, time = true }
+keymap = { c = "copy", "M-m" = """
+!make"""}
+menu.tools.key = "T"
+menu.tools.items = [["q", 'quit', "quit"]]
+`
+	c, errs := Decode(text, checkAction)
+	if len(errs) > 0 {
+		t.Fatalf("Decode() errors %v", errs)
+	}
+	want := &Config{
+		Stat:   &StatView{Time: true},
+		Keymap: []Binding{{2, "c", "copy"}, {2, "M-m", "!make"}},
+		Menus:  []Menu{{4, "tools", "T", []MenuItem{{5, "q", "quit", "quit"}}}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Decode()=%+v, want %+v", c, want)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, d := range []struct {
+		text string
+		errs string
+	}{
+		{"theme = \"pink\"", `1: unknown theme "pink", want one of default, midnight, black, white, original`},
+		{"\n\n[keymap]\nc = \"cpy\"", `4: unknown action "cpy"`},
+		{"foo = 1\nshell = [\"bash\"]", `1: unknown key "foo"; 2: shell must have %s replaced by the command`},
+		{"[stat]\nsize = \"yes\"", "2: stat.size must be a boolean"},
+		{"[menu.a]\nitems = [\n [\"x\", \"y\"],\n]", "3: menu item must be [key, label, action]"},
+		{"[menu.a]\nitems = [[\"x\", \"y\", \"quit\"], [\"x\", \"z\", \"quit\"]]", `2: duplicate key "x" in menu a`},
+		{"foo = 1.5\n[stat]\nsize = 2024-01-31", `1: unknown key "foo"; 3: stat.size must be a boolean`},
+		{"theme = \"default", `1: basic string not terminated by "`},
+		{"theme = \"default\"\ntheme = \"black\"", "2: key theme is already defined"},
+		{"[stat]\n[stat]", "2: table stat already exists"},
+		{"shell = [\"a\",\n\"b\"", "2: expected character ] but the document ended here"},
+		{"[keymap]\nc = copy", "2: incomplete number"},
+	} {
+		_, errs := Decode(d.text, checkAction)
+		if errs.Error() != d.errs {
+			t.Errorf("Decode(%q) errors %q, want %q", d.text, errs.Error(), d.errs)
+		}
+	}
+}
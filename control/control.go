@@ -0,0 +1,142 @@
+// Package control serves requests to a running goful over a Unix socket
+// in one line of JSON per connection.
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Env is the environment variable of the socket path, which overrides the
+// default and is set in the running goful for child processes.
+const Env = "GOFUL_SOCKET"
+
+// Request is a command and arguments.
+type Request struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// Pane is a directory of the workspace.
+type Pane struct {
+	Path  string `json:"path"`
+	Focus bool   `json:"focus,omitempty"`
+}
+
+// State is the state of the workspace after the command.
+type State struct {
+	Panes  []Pane   `json:"panes"`
+	Cursor string   `json:"cursor"`
+	Marked []string `json:"marked"`
+}
+
+// Response is the error of the command and the state.
+type Response struct {
+	Error string `json:"error,omitempty"`
+	State *State `json:"state,omitempty"`
+}
+
+// Handler handles a request.
+type Handler func(*Request) *Response
+
+// Server is the listening socket.
+type Server struct {
+	listener net.Listener
+	path     string
+}
+
+// serveTimeout is the time to read a request, and to write the response
+// after handling it.
+var serveTimeout = 5 * time.Second
+
+// ErrInUse is returned when another goful listens the socket.
+var ErrInUse = errors.New("socket in use by another goful")
+
+// Listen listens the socket removing a stale socket file, and serves
+// requests by the handler in goroutines.
+func Listen(path string, handler Handler) (*Server, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, ErrInUse
+	}
+	os.Remove(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	s := &Server{l, path}
+	go s.serve(handler)
+	return s, nil
+}
+
+func (s *Server) serve(handler Handler) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			var res *Response
+			req := &Request{}
+			conn.SetDeadline(time.Now().Add(serveTimeout))
+			line, err := bufio.NewReader(conn).ReadBytes('\n')
+			if err == nil {
+				err = json.Unmarshal(line, req)
+			}
+			if err != nil {
+				res = &Response{Error: fmt.Sprintf("invalid request: %v", err)}
+			} else {
+				res = handler(req)
+			}
+			conn.SetDeadline(time.Now().Add(serveTimeout))
+			json.NewEncoder(conn).Encode(res)
+		}()
+	}
+}
+
+// Close stops serving and removes the socket file.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	os.Remove(s.path)
+	return err
+}
+
+// Send sends the request to the goful listening the socket and returns the
+// response. It fails if the goful does not reply within the timeout.
+func Send(path string, req *Request, timeout time.Duration) (*Response, error) {
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("no goful running on %s", path)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	res := &Response{}
+	if err := json.NewDecoder(conn).Decode(res); err != nil {
+		return nil, fmt.Errorf("no reply from goful: %v", err)
+	}
+	if res.Error != "" {
+		return res, errors.New(res.Error)
+	}
+	return res, nil
+}
@@ -0,0 +1,90 @@
+package control
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "control")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "goful.sock")
+
+	s, err := Listen(path, func(req *Request) *Response {
+		if req.Command != "chdir" {
+			return &Response{Error: "unknown command " + req.Command}
+		}
+		return &Response{State: &State{Panes: []Pane{{Path: req.Args[0], Focus: true}}, Marked: []string{}}}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(path, nil); err != ErrInUse {
+		t.Errorf("Listen() twice=%v, want %v", err, ErrInUse)
+	}
+
+	for _, d := range []struct {
+		req   Request
+		state *State
+		err   string
+	}{
+		{Request{"chdir", []string{"/tmp"}}, &State{Panes: []Pane{{"/tmp", true}}, Marked: []string{}}, ""},
+		{Request{"quit", nil}, nil, "unknown command quit"},
+	} {
+		req := d.req
+		res, err := Send(path, &req, time.Second)
+		if err != nil && err.Error() != d.err || err == nil && d.err != "" {
+			t.Errorf("Send(%v) error %v, want %q", d.req, err, d.err)
+		}
+		if err == nil && !reflect.DeepEqual(res.State, d.state) {
+			t.Errorf("Send(%v) state %+v, want %+v", d.req, res.State, d.state)
+		}
+	}
+
+	s.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Close() left the socket file")
+	}
+	if _, err := Send(path, &Request{Command: "chdir"}, time.Second); err == nil {
+		t.Errorf("Send() succeeded after Close()")
+	}
+}
+
+func TestServeTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "control")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "goful.sock")
+	defer func(timeout time.Duration) { serveTimeout = timeout }(serveTimeout)
+	serveTimeout = 50 * time.Millisecond
+
+	s, err := Listen(path, func(req *Request) *Response { return &Response{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+	data, err := ioutil.ReadAll(conn) // sends nothing and waits closing
+	if err != nil {
+		t.Fatalf("ReadAll() error %v, want closed by the server", err)
+	}
+	if !strings.Contains(string(data), "invalid request") {
+		t.Errorf("response %q, want invalid request", data)
+	}
+}
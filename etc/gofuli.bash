@@ -0,0 +1,14 @@
+# gofuli changing the directory of the shell to the last focused directory
+# on quit by q, and not by M-q. Add to ~/.bashrc:
+#
+#   source /path/to/gofuli.bash
+gofuli() {
+    local tmp dir
+    tmp="$(mktemp)" || return
+    command gofuli --cd-file="$tmp" "$@"
+    dir="$(cat -- "$tmp")"
+    rm -f -- "$tmp"
+    if [ -n "$dir" ] && [ -d "$dir" ] && [ "$dir" != "$PWD" ]; then
+        cd -- "$dir" || return
+    fi
+}
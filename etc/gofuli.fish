@@ -0,0 +1,11 @@
+# gofuli changing the directory of the shell to the last focused directory
+# on quit by q, and not by M-q. Save as ~/.config/fish/functions/gofuli.fish
+function gofuli --wraps gofuli --description 'gofuli changing the directory on quit'
+    set -l tmp (mktemp); or return
+    command gofuli --cd-file=$tmp $argv
+    set -l dir (cat -- $tmp)
+    rm -f -- $tmp
+    if test -n "$dir" -a -d "$dir" -a "$dir" != "$PWD"
+        cd -- $dir
+    end
+end
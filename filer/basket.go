@@ -0,0 +1,140 @@
+package filer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/util"
+)
+
+// basket collects file paths across directories, saved to the path if set.
+var basket = struct {
+	path  string
+	paths []string
+}{}
+
+// LoadBasket loads the basket from the json file and saves changes to it.
+func LoadBasket(path string) error {
+	basket.path = path
+	data, err := ioutil.ReadFile(util.ExpandPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, &basket.paths)
+}
+
+func saveBasket() {
+	if basket.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(basket.paths, "", "  ")
+	if err != nil {
+		message.Error(err)
+		return
+	}
+	path := util.ExpandPath(basket.path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		message.Error(err)
+		return
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		message.Error(err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		message.Error(err)
+	}
+}
+
+// BasketPaths returns paths in the basket.
+func BasketPaths() []string {
+	return append([]string{}, basket.paths...)
+}
+
+// AddBasket adds paths to the basket and returns the number of added paths.
+func AddBasket(paths ...string) int {
+	n := 0
+	for _, path := range paths {
+		if filepath.Base(path) == ".." || basketIndex(path) >= 0 {
+			continue
+		}
+		basket.paths = append(basket.paths, path)
+		n++
+	}
+	saveBasket()
+	return n
+}
+
+// RemoveBasket removes paths from the basket.
+func RemoveBasket(paths ...string) {
+	for _, path := range paths {
+		if i := basketIndex(path); i >= 0 {
+			basket.paths = append(basket.paths[:i], basket.paths[i+1:]...)
+		}
+	}
+	saveBasket()
+}
+
+// ClearBasket removes all paths from the basket.
+func ClearBasket() {
+	basket.paths = nil
+	saveBasket()
+}
+
+func basketIndex(path string) int {
+	for i, p := range basket.paths {
+		if p == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// basketReader lists paths in the basket on the directory.
+type basketReader struct {
+	dir string
+}
+
+func (basketReader) String() string {
+	return fmt.Sprintf("Basket(바구니):(%d)", len(basket.paths))
+}
+
+func (basketReader) Read(callback func(name string)) {
+	for _, path := range basket.paths {
+		callback(path)
+	}
+}
+
+// Stat makes the file stat named by the full path with the dimmed directory.
+func (r basketReader) Stat(name string) *FileStat {
+	if name == ".." {
+		return NewFileStat(r.dir, name)
+	}
+	dir, base := filepath.Split(name)
+	fs := NewFileStat(dir, base)
+	if fs != nil {
+		fs.name = name
+		fs.prefix = dir
+	}
+	return fs
+}
+
+// ShowBasket lists paths in the basket.
+func (d *Directory) ShowBasket() {
+	d.reader = basketReader{d.Path}
+	d.read()
+}
+
+// IsBasket reports whether the directory lists the basket.
+func (d *Directory) IsBasket() bool {
+	_, ok := d.reader.(basketReader)
+	return ok
+}
@@ -0,0 +1,44 @@
+package filer
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+var collator *collate.Collator
+
+// SetCollateLocale sets the locale such as "ko" or "en-US" for sorting by
+// collation. The empty locale is taken from LC_ALL, LC_COLLATE or LANG.
+func SetCollateLocale(locale string) {
+	if locale == "" {
+		for _, env := range []string{"LC_ALL", "LC_COLLATE", "LANG"} {
+			if locale = os.Getenv(env); locale != "" {
+				break
+			}
+		}
+	}
+	// ko_KR.UTF-8 to ko-KR
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.Replace(locale, "_", "-", -1)
+	tag, err := language.Parse(locale)
+	if err != nil || locale == "C" || locale == "POSIX" {
+		tag = language.Und
+	}
+	collator = collate.New(tag)
+}
+
+func (d *Directory) lessCollate(i, j int) bool {
+	if collator == nil {
+		SetCollateLocale("")
+	}
+	n1, n2 := d.List()[i].Name(), d.List()[j].Name()
+	if c := collator.CompareString(n1, n2); c != 0 {
+		return c < 0
+	}
+	return n1 < n2
+}
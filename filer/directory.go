@@ -5,13 +5,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
-	"strconv"
 	"strings"
 
 	// "github.com/epainos/gofuli/cmdline"
 	"github.com/epainos/gofuli/look"
 	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/s3"
 	"github.com/epainos/gofuli/util"
 	"github.com/epainos/gofuli/widget"
 )
@@ -22,10 +21,19 @@ type Directory struct {
 	reader    reader
 	history   map[string]string // key: path, value: file name on cursor
 	finder    *Finder
-	Path      string   `json:"path"`
-	Sort      sortType `json:"sort_kind"`
-	myHistory []string // 첫번째는 현재위치 인덱스(previous, forward로 왔다갔다 하는 이정표).  두번째부터는 이동했었던 주소
-
+	loader    *loader
+	listed    string          // the path of files in the list
+	sizeGen   int             // the generation of directory sizes sorted by
+	view      ViewSetting     // view settings matching the path
+	paneSort  sortType        // the sort kind before applying view settings
+	visual    *visual         // the range marking
+	lastMarks []string        // paths marked before the last clearing
+	Path      string          `json:"path"`
+	Sort      sortType        `json:"sort_kind"`
+	Tree      bool            `json:"tree,omitempty"`
+	Expanded  map[string]bool `json:"expanded,omitempty"` // expanded paths in the tree view
+	Visited   []string        `json:"history,omitempty"`  // visited paths to go back and forward
+	VisitAt   int             `json:"history_index"`      // the index of the current path in visited
 }
 
 // NewDirectory creates a new directory based on specified size and coordinates.
@@ -53,14 +61,18 @@ func SetBorderStyle(style widget.BorderStyle) {
 type sortType string
 
 const (
-	sortName     sortType = "Name[^]"
-	sortNameRev  sortType = "Name[$]"
-	sortSize     sortType = "Size[^]"
-	sortSizeRev  sortType = "Size[$]"
-	sortMtime    sortType = "Time[^]"
-	sortMtimeRev sortType = "Time[$]"
-	sortExt      sortType = "Ext[^]"
-	sortExtRev   sortType = "Ext[$]"
+	sortName       sortType = "Name[^]"
+	sortNameRev    sortType = "Name[$]"
+	sortSize       sortType = "Size[^]"
+	sortSizeRev    sortType = "Size[$]"
+	sortMtime      sortType = "Time[^]"
+	sortMtimeRev   sortType = "Time[$]"
+	sortExt        sortType = "Ext[^]"
+	sortExtRev     sortType = "Ext[$]"
+	sortNatural    sortType = "Natural[^]"
+	sortNaturalRev sortType = "Natural[$]"
+	sortLocale     sortType = "Locale[^]"
+	sortLocaleRev  sortType = "Locale[$]"
 )
 
 var priorityDir = true
@@ -78,6 +90,16 @@ func ToggleShowHiddens() {
 	showHiddens = !showHiddens
 }
 
+// isHidden reports whether the name or any directory of the name begins with a dot.
+func isHidden(name string) bool {
+	for _, s := range strings.Split(filepath.ToSlash(name), "/") {
+		if strings.HasPrefix(s, ".") && s != ".." && s != "." {
+			return true
+		}
+	}
+	return false
+}
+
 type reader interface {
 	Read(callback func(name string))
 	String() string
@@ -97,9 +119,6 @@ func (s defaultReader) Read(callback func(string)) {
 	for {
 		names, err := fd.Readdirnames(100)
 		for _, name := range names {
-			if !showHiddens && strings.HasPrefix(name, ".") {
-				continue
-			}
 			callback(name)
 		}
 
@@ -125,9 +144,6 @@ func (s globPattern) Read(callback func(name string)) {
 		return
 	}
 	for _, name := range matches {
-		if !showHiddens && strings.HasPrefix(name, ".") {
-			continue
-		}
 		callback(name)
 	}
 }
@@ -144,23 +160,53 @@ func (s globDirPattern) Read(callback func(string)) {
 			return nil
 		}
 		if ok, _ := filepath.Match(string(s), info.Name()); ok {
-			if !showHiddens {
-				if strings.HasPrefix(path, ".") || strings.HasPrefix(info.Name(), ".") {
-					return nil
-				}
-			}
 			callback(path)
 		}
 		return nil
 	})
 }
 
+// flattenReader lists all files under the root up to the depth, or unlimited
+// if the depth is zero.
+type flattenReader struct {
+	root    string
+	depth   int
+	hiddens bool // walks into hidden directories
+}
+
+func (s flattenReader) String() string {
+	if s.depth > 0 {
+		return fmt.Sprintf("Flatten(펼침):(%d)", s.depth)
+	}
+	return "Flatten(펼침)"
+}
+
+func (s flattenReader) Read(callback func(string)) {
+	_ = filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == s.root {
+			return nil
+		}
+		name, _ := filepath.Rel(s.root, path)
+		if !s.hiddens && strings.HasPrefix(info.Name(), ".") || s.depth > 0 && strings.Count(name, string(filepath.Separator)) >= s.depth {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			callback(name)
+		}
+		return nil
+	})
+}
+
 func (d *Directory) init4json() {
 	d.ListBox = widget.NewListBox(0, 0, 0, 0, "")
 	d.history = map[string]string{}
 	d.SetTitle(util.AbbrPath(d.Path))
 	d.SetColumn(1)
-	d.reader = defaultReader(".")
+	d.reader = readerFor(d.Path)
+	d.applyView()
 }
 
 // Resize the window and the finder.
@@ -174,6 +220,7 @@ func (d *Directory) Resize(x, y, width, height int) {
 
 // Finder starts a finder in the directory for filtering files.
 func (d *Directory) Finder() {
+	d.cancelLoad()
 	x, y := d.LeftTop()
 	d.finder = NewFinder(d, x, y+d.Height()-1, d.Width(), 1)
 	d.ResizeRelative(0, 0, 0, -1)
@@ -181,130 +228,135 @@ func (d *Directory) Finder() {
 
 // EnterDir changes the directory to a path on the cursor.
 func (d *Directory) EnterDir() {
-	if 0 >= len(d.myHistory) {
-	} else {
-		myIndex, _ := strconv.Atoi(d.myHistory[0])
-		if len(d.myHistory)-1 > myIndex {
-			d.myHistory = d.myHistory[:myIndex+1]
-		}
-	}
 	d.Chdir(d.File().Name())
 }
 
 // Reset marking or reader.
 func (d *Directory) Reset() {
-	if d.IsMark() {
+	if d.IsLoading() {
+		d.cancelLoad()
+	} else if d.IsVisual() {
+		d.visual = nil
+	} else if d.IsMark() {
 		d.MarkClear()
-	} else if _, ok := d.reader.(defaultReader); !ok {
+	} else if !isDefaultReader(d.reader) {
 		name := d.File().Name()
-		d.reader = defaultReader(".")
+		d.reader = readerFor(d.Path)
 		d.read()
-		d.SetCursorByName(name)
-		d.SetOffsetCenteredCursor()
+		d.cursorTo(name)
 	}
 }
 
 // Chdir changes the current directory and reads a new path by the default reader.
 // Sets the cursor to the history name or to the previous directory name if parent destinats.
 func (d *Directory) Chdir(path string) {
-	path = util.ExpandPath(path)
-	path = filepath.Clean(path)
+	old := d.Path
+	if d.chdir(path) {
+		d.visit(old, d.Path)
+	}
+}
+
+func (d *Directory) chdir(path string) bool {
+	if s3.IsPath(path) {
+		path = s3.Clean(path)
+	} else if d.IsRemote() && path != "" && path[0] != '~' && !filepath.IsAbs(path) {
+		path = s3.Clean(d.Path + "/" + path)
+	} else {
+		path = util.ExpandPath(path)
+		path = filepath.Clean(path)
 
-	if !filepath.IsAbs(path) {
-		path, _ = filepath.Abs(filepath.Join(d.Path, path))
+		if !filepath.IsAbs(path) {
+			path, _ = filepath.Abs(filepath.Join(d.Path, path))
+		}
 	}
 	olddir := filepath.Base(d.Path)
 	parent := filepath.Dir(d.Path)
+	if d.IsRemote() {
+		parent = s3.Clean(d.Path + "/..")
+	}
 
 	if d.finder != nil {
 		d.finder.exitNotRead()
 	}
 
-	if err := os.Chdir(path); err != nil {
-		message.Error(err)
-		return
+	if !s3.IsPath(path) {
+		if err := os.Chdir(path); err != nil {
+			message.Error(err)
+			return false
+		}
 	}
 	if !d.IsEmpty() {
 		d.history[d.Path] = d.File().Name()
 	}
 	d.SetTitle(util.AbbrPath(path))
 	d.Path = path
-	d.reader = defaultReader(".")
+	d.reader = readerFor(path)
+	d.applyView()
 	d.read()
 
-	d.myHistory = AddHistory(d.myHistory, path)
-
 	if name, ok := d.history[d.Path]; ok {
-		d.SetCursorByName(name)
-		d.SetOffsetCenteredCursor()
+		d.cursorTo(name)
 	} else if path == parent {
-		d.SetCursorByName(olddir)
-		d.SetOffsetCenteredCursor()
+		d.cursorTo(olddir)
 	} else {
 		d.SetCursor(0)
 	}
-
-}
-func AddHistory(myHistory []string, path string) []string {
-	if len(myHistory) == 0 {
-		myHistory = append(myHistory, "0")
-	}
-	if len(myHistory) >= 20 {
-		myHistory = append(myHistory[:1], myHistory[2:]...)
-	}
-	myHistory = append(myHistory, path)
-
-	myHistory[0] = strconv.Itoa(len(myHistory) - 1)
-	return myHistory
+	return true
 }
 
-// goPreviousFoler
-func (d *Directory) GoPreviousFolder() {
-	if len(d.myHistory) == 0 || d.myHistory[1] == "1" {
+// Glob sets a reader to matching pattern in the current directory.
+func (d *Directory) Glob(pattern string) {
+	if d.IsRemote() {
+		message.Errorf("Glob is not supported in %s", d.Path)
 		return
 	}
-	myIndex, _ := strconv.Atoi(d.myHistory[0])
-	if 1 >= myIndex {
+	d.reader = globPattern(pattern)
+	d.read()
+}
+
+// Globdir sets a reader to matching pattern in the directory includeing sub directories.
+func (d *Directory) Globdir(pattern string) {
+	if d.IsRemote() {
+		message.Errorf("Globdir is not supported in %s", d.Path)
 		return
-	} else {
-		myIndex--
 	}
-
-	path := d.myHistory[myIndex]
-	d.Chdir(path)
-	d.myHistory = d.myHistory[:len(d.myHistory)-1]
-	d.myHistory[0] = strconv.Itoa(myIndex)
-
+	d.reader = globDirPattern(pattern)
+	d.read()
 }
 
-// goForwardFoler
-func (d *Directory) GoFowardFolder() {
-	myIndex, _ := strconv.Atoi(d.myHistory[0])
-	if myIndex >= len(d.myHistory)-1 {
+// Flatten lists all files under the directory with relative paths up to the
+// depth, or unlimited if the depth is zero.
+func (d *Directory) Flatten(depth int) {
+	if d.IsRemote() {
+		message.Errorf("Flatten is not supported in %s", d.Path)
 		return
 	}
-	myIndex++
-
-	path := d.myHistory[myIndex]
-	d.Chdir(path)
-	d.myHistory = d.myHistory[:len(d.myHistory)-1]
-	d.myHistory[0] = strconv.Itoa(myIndex)
-
+	d.reader = flattenReader{d.Path, depth, d.showHiddens()}
+	d.read()
 }
 
-// Glob sets a reader to matching pattern in the current directory.
-func (d *Directory) Glob(pattern string) {
-	d.reader = globPattern(pattern)
+// Unflatten lists the directory as it is again.
+func (d *Directory) Unflatten() {
+	name := strings.SplitN(d.File().Name(), string(filepath.Separator), 2)[0]
+	d.reader = readerFor(d.Path)
 	d.read()
+	d.cursorTo(name)
 }
 
-// Globdir sets a reader to matching pattern in the directory includeing sub directories.
-func (d *Directory) Globdir(pattern string) {
-	d.reader = globDirPattern(pattern)
-	d.read()
+// IsFlatten reports whether the directory lists files recursively.
+func (d *Directory) IsFlatten() bool {
+	_, ok := d.reader.(flattenReader)
+	return ok
 }
 
 func (d *Directory) read() {
+	if d.finder == nil && (isDefaultReader(d.reader) || d.IsFlatten() || d.IsGrep() || d.IsFind()) {
+		d.load()
+		return
+	}
+	d.cancelLoad()
+	d.listed = d.Path
+
 	marked := make(map[string]bool, d.MarkCount())
 	for _, e := range d.List() {
 		if e.(*FileStat).IsMarked() {
@@ -312,8 +364,12 @@ func (d *Directory) read() {
 		}
 	}
 
+	hiddens := d.showHiddens() || d.IsBasket() // full paths may be in hidden directories
 	callback := func(name string) {
-		if fs := NewFileStat(d.Path, name); fs != nil {
+		if !hiddens && isHidden(name) {
+			return
+		}
+		if fs := d.stat(name); fs != nil {
 			d.AppendList(fs)
 		}
 	}
@@ -324,9 +380,10 @@ func (d *Directory) read() {
 		d.reader.Read(callback)
 	}
 	if d.IsEmpty() {
-		d.AppendList(NewFileStat(d.Path, ".."))
+		d.AppendList(d.stat(".."))
 	}
-	sort.Sort(d)
+	d.expand()
+	d.sortList()
 
 	for _, e := range d.List() {
 		if _, ok := marked[e.(*FileStat).Path()]; ok {
@@ -336,12 +393,17 @@ func (d *Directory) read() {
 }
 
 func (d *Directory) reload() {
+	if d.IsRemote() {
+		d.read()
+		return
+	}
 	if err := os.Chdir(d.Path); err != nil {
 		message.Error(err)
 		home, _ := os.UserHomeDir()
 		d.Chdir(home)
 		return
 	}
+	refreshGit(d.Path)
 	d.read()
 }
 
@@ -356,7 +418,7 @@ func (d *Directory) Base() string { return filepath.Base(d.Path) }
 func (d *Directory) sortBy(typ sortType) {
 	d.Sort = typ
 	name := d.File().Name()
-	sort.Sort(d)
+	d.sortList()
 	d.SetCursorByName(name)
 	d.SetOffsetCenteredCursor()
 }
@@ -385,9 +447,21 @@ func (d *Directory) SortExt() { d.sortBy(sortExt) }
 // SortExtDec sorts files in descending order by the file extension.
 func (d *Directory) SortExtDec() { d.sortBy(sortExtRev) }
 
+// SortNatural sorts files in ascending order by the file name comparing numbers.
+func (d *Directory) SortNatural() { d.sortBy(sortNatural) }
+
+// SortNaturalDec sorts files in descending order by the file name comparing numbers.
+func (d *Directory) SortNaturalDec() { d.sortBy(sortNaturalRev) }
+
+// SortLocale sorts files in ascending order by the file name collated for the locale.
+func (d *Directory) SortLocale() { d.sortBy(sortLocale) }
+
+// SortLocaleDec sorts files in descending order by the file name collated for the locale.
+func (d *Directory) SortLocaleDec() { d.sortBy(sortLocaleRev) }
+
 // Less compares based on Sort.
 func (d *Directory) Less(i, j int) bool {
-	if priorityDir {
+	if d.priorityDir() {
 		id := d.List()[i].(*FileStat).stat.IsDir()
 		jd := d.List()[j].(*FileStat).stat.IsDir()
 		if !(id && jd) && (id || jd) {
@@ -411,6 +485,18 @@ func (d *Directory) Less(i, j int) bool {
 		return d.lessExt(i, j)
 	case sortExtRev:
 		return d.lessExt(j, i)
+	case sortNatural:
+		return util.NaturalLess(d.List()[i].Name(), d.List()[j].Name())
+	case sortNaturalRev:
+		return util.NaturalLess(d.List()[j].Name(), d.List()[i].Name())
+	case sortLocale:
+		return d.lessCollate(i, j)
+	case sortLocaleRev:
+		return d.lessCollate(j, i)
+	default:
+		if keys := sortKeys(d.Sort); keys != nil {
+			return d.lessKeys(i, j, keys)
+		}
 	}
 	return d.List()[i].Name() < d.List()[j].Name()
 }
@@ -429,8 +515,8 @@ func (d *Directory) lessMtime(i, j int) bool {
 func (d *Directory) lessSize(i, j int) bool {
 	f1 := d.List()[i].(*FileStat)
 	f2 := d.List()[j].(*FileStat)
-	s1 := f1.Size()
-	s2 := f2.Size()
+	s1 := f1.size()
+	s2 := f2.size()
 	if s1 != s2 {
 		return s1 < s2
 	}
@@ -473,8 +559,10 @@ func (d *Directory) InvertMark() {
 	}
 }
 
-// MarkClear clears all file marks.
+// MarkClear clears all file marks. The marks can be restored by RestoreMarks.
 func (d *Directory) MarkClear() {
+	d.visual = nil
+	d.saveMarks()
 	for _, e := range d.List() {
 		e.(*FileStat).Markoff()
 	}
@@ -562,13 +650,19 @@ func (d *Directory) MarkfileQuotedPaths() []string {
 }
 
 func (d *Directory) drawFooter() {
-	s := fmt.Sprintf("[%d/%d] %s(%d) %s %s",
-		d.MarkCount(), len(d.List()), d.ScrollRate(), d.Cursor(), d.Sort, d.reader.String())
+	s := fmt.Sprintf("[%d/%d] %s(%d) %s %s%s%s",
+		d.MarkCount(), len(d.List()), d.ScrollRate(), d.Cursor(), d.Sort, d.reader.String(), d.gitInfo(), d.loadingStatus())
+	if d.IsVisual() {
+		s += " VISUAL"
+	}
 	x, y := d.LeftBottom()
 	widget.SetCells(x, y, s, look.Default())
 }
 
 func (d *Directory) drawFiles(focus bool) {
+	global := statView
+	statView = d.statView()
+	defer func() { statView = global }()
 	height := d.Height() - 2
 	row := 1
 	shift := 0
@@ -594,8 +688,11 @@ func (d *Directory) drawFiles(focus bool) {
 }
 
 func (d *Directory) draw(focus bool) {
+	d.drain()
+	d.resortBySize()
 	d.AdjustCursor()
 	d.AdjustOffset()
+	d.syncVisual()
 	d.Border()
 	d.drawFiles(focus)
 	d.drawFooter()
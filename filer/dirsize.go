@@ -0,0 +1,108 @@
+package filer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/epainos/gofuli/s3"
+	"github.com/epainos/gofuli/util"
+)
+
+const dirSizeWorkers = 2
+
+var dirSizeView = false
+
+// ToggleDirSizeView toggles calculating directory sizes recursively.
+func ToggleDirSizeView() { dirSizeView = !dirSizeView }
+
+type dirSizeJob struct {
+	path string
+	ds   *dirSize
+}
+
+type dirSize struct {
+	mtime time.Time
+	size  int64
+	ready bool
+}
+
+// dirSizes caches recursive directory sizes by the path and the modified time.
+var dirSizes = struct {
+	sync.Mutex
+	cache map[string]*dirSize
+	queue chan *dirSizeJob
+	gen   int // increases whenever a size is calculated
+}{
+	cache: map[string]*dirSize{},
+}
+
+// recursiveSize returns the total size of files under the directory. Reports
+// false and calculates in background if the size is not cached yet.
+func recursiveSize(path string, mtime time.Time) (int64, bool) {
+	dirSizes.Lock()
+	defer dirSizes.Unlock()
+	if ds, ok := dirSizes.cache[path]; ok && ds.mtime.Equal(mtime) {
+		return ds.size, ds.ready
+	}
+	ds := &dirSize{mtime: mtime}
+	dirSizes.cache[path] = ds
+	if dirSizes.queue == nil {
+		dirSizes.queue = make(chan *dirSizeJob, 1024)
+		for i := 0; i < dirSizeWorkers; i++ {
+			go calcDirSizes()
+		}
+	}
+	select {
+	case dirSizes.queue <- &dirSizeJob{path, ds}:
+	default:
+		delete(dirSizes.cache, path) // retries on next drawing
+	}
+	return 0, false
+}
+
+func calcDirSizes() {
+	for job := range dirSizes.queue {
+		size, _ := util.CalcSizeCount(job.path)
+		dirSizes.Lock()
+		job.ds.size = size
+		job.ds.ready = true
+		dirSizes.gen++
+		dirSizes.Unlock()
+		wakeup()
+	}
+}
+
+func dirSizeGen() int {
+	dirSizes.Lock()
+	defer dirSizes.Unlock()
+	return dirSizes.gen
+}
+
+// hasRecursiveSize reports whether the file size is calculated recursively.
+func (f *FileStat) hasRecursiveSize() bool {
+	return dirSizeView && f.IsDir() && f.name != ".." && !s3.IsPath(f.path)
+}
+
+// size returns the file size or the recursive size of directory.
+func (f *FileStat) size() int64 {
+	if f.hasRecursiveSize() {
+		size, _ := recursiveSize(f.Path(), f.ModTime())
+		return size
+	}
+	return f.Size()
+}
+
+// resortBySize sorts again if directory sizes are calculated after sorting.
+func (d *Directory) resortBySize() {
+	if !dirSizeView || !d.hasSortField("Size") {
+		return
+	}
+	gen := dirSizeGen()
+	if gen == d.sizeGen || d.IsEmpty() {
+		return
+	}
+	d.sizeGen = gen
+	name := d.File().Name()
+	d.sortList()
+	d.SetCursorByName(name)
+}
@@ -50,7 +50,11 @@ type FileStat struct {
 	path        string      // full path of file
 	name        string      // base name of path or ".." as upper directory
 	display     string      // display name for draw
+	indent      string      // indentation guides in the tree view
+	prefix      string      // directory part of a relative name
 	marked      bool        // marked whether
+	matches     []int       // rune positions in the name matched by the finder
+	grep        *grepMatch  // contents matched by grep
 	myColor     tcell.Style
 }
 
@@ -85,7 +89,26 @@ func NewFileStat(dir string, name string) *FileStat {
 	if err != nil {
 		stat = lstat
 	}
+	return newFileStat(path, name, lstat, stat)
+}
 
+// newRelFileStat creates a new file stat of the relative path in the directory.
+// The display name is the base name and the directory part is drawn dimmed.
+func newRelFileStat(dir, name string) *FileStat {
+	parent, base := filepath.Split(name)
+	if parent == "" {
+		return NewFileStat(dir, name)
+	}
+	fs := NewFileStat(filepath.Join(dir, parent), base)
+	if fs != nil {
+		fs.name = name
+		fs.prefix = parent
+	}
+	return fs
+}
+
+// newFileStat creates a new file stat with a display name decorated by the file type.
+func newFileStat(path, name string, lstat, stat os.FileInfo) *FileStat {
 	var display string
 	d := tcell.StyleDefault
 	myColor := d.Foreground(tcell.ColorGray)
@@ -273,10 +296,16 @@ func (f *FileStat) suffix() string {
 	return ""
 }
 
-func (f *FileStat) states() string {
+func (f *FileStat) states(git byte) string {
 	ret := f.Ext()
 	if statView.size {
-		if f.stat.IsDir() {
+		if f.hasRecursiveSize() {
+			if size, ok := recursiveSize(f.Path(), f.ModTime()); ok {
+				ret += fmt.Sprintf("%8s", util.FormatSize(size))
+			} else {
+				ret += fmt.Sprintf("%8s", "<...>")
+			}
+		} else if f.stat.IsDir() {
 			ret += fmt.Sprintf("%8s", "<DIR>")
 		} else {
 			ret += fmt.Sprintf("%8s", util.FormatSize(f.stat.Size()))
@@ -288,6 +317,9 @@ func (f *FileStat) states() string {
 	if statView.time {
 		ret += " " + f.stat.ModTime().Format(timeFormat)
 	}
+	if git != 0 {
+		ret += " " + string(git)
+	}
 	return ret
 }
 
@@ -317,15 +349,79 @@ func (f *FileStat) Draw(x, y, width int, focus bool) {
 	if focus {
 		style = style.Reverse(true)
 	}
-	states := f.states()
+	git := f.gitStatus()
+	states := f.states(git)
 	width -= len(states)
 	pre := " "
 	if f.marked {
 		pre = "*"
 	}
-	s := pre + f.display + f.suffix()
-	s = runewidth.Truncate(s, width, "~")
+	s := pre + f.indent + f.prefix + f.display + f.suffix()
+	head := runewidth.StringWidth(s)
+	s = runewidth.Truncate(s+f.note(), width, "~")
 	s = runewidth.FillRight(s, width)
+	start := x
 	x = widget.SetCells(x, y, s, style)
-	widget.SetCells(x, y, states, style)
+	if dim := f.indent + f.prefix; dim != "" && width > 1 {
+		widget.SetCells(start+1, y, runewidth.Truncate(dim, width-1, ""), style.Dim(true))
+	}
+	if note := f.note(); note != "" && head < width-1 {
+		widget.SetCells(start+head, y, runewidth.Truncate(note, width-head, "~"), style.Dim(true))
+	}
+	if len(f.matches) > 0 {
+		f.drawMatches(start, y, width, focus)
+	}
+	x = widget.SetCells(x, y, states, style)
+	if git != 0 && git != gitClean {
+		gs := gitLook(git)
+		if focus {
+			gs = gs.Reverse(true)
+		}
+		widget.SetCells(x-1, y, string(git), gs)
+	}
+}
+
+// drawMatches highlights characters of the name matched by the finder.
+func (f *FileStat) drawMatches(x, y, width int, focus bool) {
+	name := []rune(f.name)
+	prefix := []rune(f.prefix)
+	base := name[len(name)-len([]rune(filepath.Base(f.name))):]
+	stem := []rune(f.stem(string(base)))
+	if !strings.HasSuffix(f.display, string(stem)) {
+		return // changed by SetDisplay
+	}
+	limit := width
+	if runewidth.StringWidth(" "+f.indent+f.prefix+f.display+f.suffix()+f.note()) > width {
+		limit-- // truncated by ~
+	}
+	style := look.Highlight()
+	if focus {
+		style = style.Reverse(true)
+	}
+	head := runewidth.StringWidth(" " + f.indent)
+	namex := head + runewidth.StringWidth(f.prefix+strings.TrimSuffix(f.display, string(stem)))
+	for _, p := range f.matches {
+		var cx int
+		switch offset := p - (len(name) - len(base)); {
+		case p < len(prefix) && len(prefix) == len(name)-len(base):
+			cx = head + runewidth.StringWidth(string(prefix[:p]))
+		case offset >= 0 && offset < len(stem):
+			cx = namex + runewidth.StringWidth(string(stem[:offset]))
+		default:
+			continue // not drawn in the name column such as the extension
+		}
+		c := name[p]
+		if cx+runewidth.RuneWidth(c) > limit {
+			continue
+		}
+		widget.SetCells(x+cx, y, string(c), style)
+	}
+}
+
+// stem returns the base name drawn in the display name.
+func (f *FileStat) stem(base string) string {
+	if f.stat.IsDir() {
+		return base
+	}
+	return util.RemoveExt(base)
 }
@@ -22,6 +22,7 @@ type Filer struct {
 	extmap     widget.Extmap
 	Workspaces []*Workspace `json:"workspaces"`
 	Current    int          `json:"current"`
+	Recent     []string     `json:"recent,omitempty"` // visited paths across directories
 }
 
 // New creates a new filer based on specified size and coordinates.
@@ -91,11 +92,13 @@ func NewFromState(path string, x, y, width, height int) *Filer {
 		}
 		ws.allocate()
 	}
+	recentPaths = filer.Recent
 	return filer
 }
 
 // SaveState saves the filer state to the file.
 func (f *Filer) SaveState(path string) error {
+	f.Recent = RecentPaths()
 	jsondata, err := json.MarshalIndent(f, "", "  ")
 	if err != nil {
 		return err
@@ -228,6 +231,8 @@ func (f *Filer) Input(key string) {
 	if ext, ok := f.extmap[key]; ok {
 		if callback, ok := ext[".dir"]; ok && (f.File().IsDir() || f.File().stat.IsDir()) {
 			callback()
+		} else if callback, ok := ext[".grep"]; ok && f.File().IsGrep() {
+			callback()
 		} else if callback, ok := ext[".exec"]; ok && f.File().IsExec() {
 			callback()
 		} else if callback, ok := ext[f.File().Ext()]; ok {
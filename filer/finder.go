@@ -2,9 +2,11 @@ package filer
 
 import (
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/epainos/gofuli/look"
+	"github.com/epainos/gofuli/util"
 	"github.com/epainos/gofuli/widget"
 	"github.com/mattn/go-runewidth"
 )
@@ -22,6 +24,11 @@ var finderHistory = make([]string, 0, 100)
 
 var finderKeymap func(*Finder) widget.Keymap
 
+var fuzzyFinder = false
+
+// SetFuzzyFinder sets the finder to fuzzy matching or regexp matching.
+func SetFuzzyFinder(fuzzy bool) { fuzzyFinder = fuzzy }
+
 func GetFinderHistory() string {
 	return strings.Join(finderHistory, ", ")
 }
@@ -88,7 +95,22 @@ func (f *Finder) addHistory() {
 	}
 }
 
+// ToggleFuzzy toggles fuzzy matching and regexp matching.
+func (f *Finder) ToggleFuzzy() {
+	fuzzyFinder = !fuzzyFinder
+	f.Edithook()
+}
+
+// ranked reports whether files are listed in the order of fuzzy scores.
+func (f *Finder) ranked() bool {
+	return fuzzyFinder && f.String() != ""
+}
+
 func (f *Finder) find(callback func(name string)) {
+	if fuzzyFinder {
+		f.findFuzzy(callback)
+		return
+	}
 	expr := f.String()
 	if expr == strings.ToLower(expr) {
 		expr = "(?i)" + expr // case insensitive
@@ -108,8 +130,49 @@ func (f *Finder) find(callback func(name string)) {
 			callback(name)
 		}
 	}
+	f.done(current)
+}
+
+// findFuzzy lists files matching fuzzily in the order of scores and
+// highlights matched characters.
+func (f *Finder) findFuzzy(callback func(name string)) {
+	type match struct {
+		name      string
+		score     int
+		positions []int
+	}
+	matches := []match{}
+	for _, name := range f.names {
+		if score, positions, ok := util.FuzzyMatch(f.String(), name); ok {
+			matches = append(matches, match{name, score, positions})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	current := ""
+	if !f.dir.IsEmpty() && !f.ranked() {
+		current = f.dir.CurrentContent().Name()
+	}
+	f.dir.ClearList()
+	for _, m := range matches {
+		n := len(f.dir.List())
+		callback(m.name)
+		if len(f.dir.List()) > n {
+			f.dir.List()[n].(*FileStat).matches = m.positions
+		}
+	}
+	f.done(current)
+	if f.ranked() {
+		f.dir.SetCursor(0) // the best match
+		f.dir.SetOffsetCenteredCursor()
+	}
+}
+
+func (f *Finder) done(current string) {
 	if f.dir.IsEmpty() {
-		f.dir.AppendList(NewFileStat(f.dir.Path, ".."))
+		f.dir.AppendList(f.dir.stat(".."))
 	}
 	if current != "" {
 		f.dir.SetCursorByName(current)
@@ -121,7 +184,11 @@ func (f *Finder) find(callback func(name string)) {
 func (f *Finder) Draw(focus bool) {
 	f.Clear()
 	x, y := f.LeftTop()
-	s := "Find: " + f.String()
+	mode := "regexp"
+	if fuzzyFinder {
+		mode = "fuzzy"
+	}
+	s := "Find(" + mode + "): " + f.String()
 	x = widget.SetCells(x, y, s, look.Finder())
 	spacewidth := f.Width() - runewidth.StringWidth(s)
 	if spacewidth > 0 {
@@ -140,8 +207,7 @@ func (f *Finder) Exit() {
 		name = f.dir.File().Name()
 	}
 	f.dir.read()
-	f.dir.SetCursorByName(name)
-	f.dir.SetOffsetCenteredCursor()
+	f.dir.cursorTo(name)
 }
 
 func (f *Finder) exitNotRead() {
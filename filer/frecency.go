@@ -0,0 +1,193 @@
+package filer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/epainos/gofuli/util"
+)
+
+// frecencyMaxAge is the total rank to age ranks of all paths.
+const frecencyMaxAge = 10000
+
+type frecencyEntry struct {
+	Rank float64 `json:"rank"`
+	Time int64   `json:"time"` // the last visited unix time
+}
+
+// frecency ranks visited directories by frequency and recency.
+var frecency = struct {
+	path    string
+	entries map[string]*frecencyEntry
+}{entries: map[string]*frecencyEntry{}}
+
+// LoadFrecency loads the database of visited directories from the json file
+// to save by SaveFrecency.
+func LoadFrecency(path string) error {
+	frecency.path = path
+	data, err := ioutil.ReadFile(util.ExpandPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, &frecency.entries); err != nil {
+		return err
+	}
+	if frecency.entries == nil {
+		frecency.entries = map[string]*frecencyEntry{}
+	}
+	return nil
+}
+
+// SaveFrecency saves the database of visited directories.
+func SaveFrecency() error {
+	if frecency.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(frecency.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := util.ExpandPath(frecency.path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// addFrecency increases the rank of the local path and ages all ranks if the
+// total exceeds the max age.
+func addFrecency(path string, now time.Time) {
+	if !filepath.IsAbs(path) {
+		return
+	}
+	e, ok := frecency.entries[path]
+	if !ok {
+		e = &frecencyEntry{}
+		frecency.entries[path] = e
+	}
+	e.Rank++
+	e.Time = now.Unix()
+	ageFrecency()
+}
+
+func ageFrecency() {
+	total := 0.0
+	for _, e := range frecency.entries {
+		total += e.Rank
+	}
+	if total <= frecencyMaxAge {
+		return
+	}
+	factor := 0.9 * frecencyMaxAge / total
+	for path, e := range frecency.entries {
+		e.Rank *= factor
+		if e.Rank < 1 {
+			delete(frecency.entries, path)
+		}
+	}
+}
+
+// score weights the rank by the time since the last visit.
+func (e *frecencyEntry) score(now time.Time) float64 {
+	switch d := now.Sub(time.Unix(e.Time, 0)); {
+	case d < time.Hour:
+		return e.Rank * 4
+	case d < 24*time.Hour:
+		return e.Rank * 2
+	case d < 7*24*time.Hour:
+		return e.Rank / 2
+	}
+	return e.Rank / 4
+}
+
+// jumpMatch reports whether keywords appear in the path in order and the last
+// keyword appears in the base name. Case insensitive when keywords are
+// lowercase only.
+func jumpMatch(path string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return true
+	}
+	if strings.Join(keywords, "") == strings.ToLower(strings.Join(keywords, "")) {
+		path = strings.ToLower(path)
+	}
+	rest := path
+	for _, keyword := range keywords {
+		i := strings.Index(rest, keyword)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(keyword):]
+	}
+	return strings.Contains(filepath.Base(path), keywords[len(keywords)-1])
+}
+
+// JumpCandidates returns visited directories matching keywords ordered by
+// frecency except the directory. Removed directories are forgotten.
+func JumpCandidates(keywords []string, except string) []string {
+	now := time.Now()
+	paths := []string{}
+	for path := range frecency.entries {
+		if path == except || !jumpMatch(path, keywords) {
+			continue
+		}
+		if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+			delete(frecency.entries, path)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		si, sj := frecency.entries[paths[i]].score(now), frecency.entries[paths[j]].score(now)
+		if si != sj {
+			return si > sj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
+
+// ImportFrecency imports lines of the score and the path separated by spaces
+// or a tab, such as autojump.txt of autojump or the output of
+// "zoxide query --list --score", and returns the number of imported paths.
+func ImportFrecency(r io.Reader) (int, error) {
+	now := time.Now().Unix()
+	n := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			continue
+		}
+		rank, err := strconv.ParseFloat(line[:i], 64)
+		path := strings.TrimSpace(line[i:])
+		if err != nil || math.IsNaN(rank) || rank <= 0 || !filepath.IsAbs(path) {
+			continue
+		}
+		e, ok := frecency.entries[path]
+		if !ok {
+			e = &frecencyEntry{Time: now}
+			frecency.entries[path] = e
+		}
+		e.Rank += rank
+		n++
+	}
+	ageFrecency()
+	return n, scanner.Err()
+}
@@ -0,0 +1,54 @@
+package filer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJumpMatch(t *testing.T) {
+	for _, d := range []struct {
+		path     string
+		keywords string
+		result   bool
+	}{
+		{"/home/user/proj/api", "proj api", true},
+		{"/home/user/proj/api", "api proj", false},
+		{"/home/user/proj/api", "proj", false},
+		{"/home/user/Proj/API", "proj api", true},
+		{"/home/user/Proj/API", "Proj api", false},
+		{"/home/user/proj/api", "", true},
+		{"/home/user/proj/api-v2", "pr v2", true},
+	} {
+		if result := jumpMatch(d.path, strings.Fields(d.keywords)); result != d.result {
+			t.Errorf("jumpMatch(%q, %q)=%v, want %v", d.path, d.keywords, result, d.result)
+		}
+	}
+}
+
+func TestFrecencyScore(t *testing.T) {
+	now := time.Now()
+	for _, d := range []struct {
+		recent, old *frecencyEntry
+	}{
+		{&frecencyEntry{1, now.Unix()}, &frecencyEntry{1, now.Add(-48 * time.Hour).Unix()}},
+		{&frecencyEntry{2, now.Add(-2 * time.Hour).Unix()}, &frecencyEntry{5, now.Add(-30 * 24 * time.Hour).Unix()}},
+	} {
+		if r, o := d.recent.score(now), d.old.score(now); r <= o {
+			t.Errorf("score %v of %v <= score %v of %v", r, *d.recent, o, *d.old)
+		}
+	}
+}
+
+func TestImportFrecency(t *testing.T) {
+	saved := frecency.entries
+	defer func() { frecency.entries = saved }()
+	frecency.entries = map[string]*frecencyEntry{}
+	n, err := ImportFrecency(strings.NewReader("10.0\t/home/user/proj\n  4 /srv/my data\nfoo /tmp\n3 relative\n"))
+	if err != nil || n != 2 {
+		t.Errorf("ImportFrecency()=%d, %v, want 2, nil", n, err)
+	}
+	if e, ok := frecency.entries["/srv/my data"]; !ok || e.Rank != 4 {
+		t.Errorf("ImportFrecency() entry %q=%v", "/srv/my data", e)
+	}
+}
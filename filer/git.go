@@ -0,0 +1,295 @@
+package filer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Git status marks of files.
+const (
+	gitClean      = ' '
+	gitUntracked  = '?'
+	gitStaged     = 'S'
+	gitModified   = 'M'
+	gitConflicted = 'U'
+	gitIgnored    = '!'
+)
+
+var gitView = true
+
+// ToggleGitView toggles the git status view in git working trees.
+func ToggleGitView() { gitView = !gitView }
+
+// gitState is a result of git status in the working tree.
+type gitState struct {
+	branch string
+	ahead  int
+	behind int
+	files  map[string]byte // marks by slash separated paths relative to the root
+	dirs   map[string]byte // rolled-up marks of directories
+}
+
+type gitRepo struct {
+	state   *gitState
+	loading bool
+	dirty   bool // refreshed while loading
+}
+
+// gitRepos caches git status by the root of working trees.
+var gitRepos = struct {
+	sync.Mutex
+	roots map[string]string // the root of directories, empty if not in a working tree
+	repos map[string]*gitRepo
+}{
+	roots: map[string]string{},
+	repos: map[string]*gitRepo{},
+}
+
+// gitRoot returns the root of the working tree containing the directory.
+// Must be called with the lock.
+func gitRoot(dir string) string {
+	if root, ok := gitRepos.roots[dir]; ok {
+		return root
+	}
+	root := ""
+	if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+		root = dir
+	} else if parent := filepath.Dir(dir); parent != dir {
+		root = gitRoot(parent)
+	}
+	gitRepos.roots[dir] = root
+	return root
+}
+
+// gitStatusOf returns the root and the status of the working tree containing
+// the directory, and starts git status in background if not cached.
+func gitStatusOf(dir string) (string, *gitState) {
+	if !gitView || !filepath.IsAbs(dir) {
+		return "", nil
+	}
+	gitRepos.Lock()
+	defer gitRepos.Unlock()
+	root := gitRoot(dir)
+	if root == "" {
+		return "", nil
+	}
+	repo, ok := gitRepos.repos[root]
+	if !ok {
+		repo = &gitRepo{}
+		gitRepos.repos[root] = repo
+		refreshGitRepo(root, repo)
+	}
+	return root, repo.state
+}
+
+// refreshGit runs git status again for the working tree of the directory.
+func refreshGit(dir string) {
+	if !gitView || !filepath.IsAbs(dir) {
+		return
+	}
+	gitRepos.Lock()
+	defer gitRepos.Unlock()
+	delete(gitRepos.roots, dir) // .git may be created or removed
+	root := gitRoot(dir)
+	if root == "" {
+		return
+	}
+	repo, ok := gitRepos.repos[root]
+	if !ok {
+		repo = &gitRepo{}
+		gitRepos.repos[root] = repo
+	}
+	refreshGitRepo(root, repo)
+}
+
+// refreshGitRepo must be called with the lock.
+func refreshGitRepo(root string, repo *gitRepo) {
+	if repo.loading {
+		repo.dirty = true
+		return
+	}
+	repo.loading = true
+	go func() {
+		for {
+			state, err := gitStatus(root)
+			if err != nil {
+				state = &gitState{}
+			}
+			gitRepos.Lock()
+			repo.state = state
+			if !repo.dirty {
+				repo.loading = false
+				gitRepos.Unlock()
+				break
+			}
+			repo.dirty = false
+			gitRepos.Unlock()
+		}
+		wakeup()
+	}()
+}
+
+// gitStatus runs git status in the working tree and parses the output.
+func gitStatus(root string) (*gitState, error) {
+	out, err := exec.Command("git", "-C", root, "status", "--porcelain=v1", "-b", "-z", "--ignored").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseGitStatus(out), nil
+}
+
+func parseGitStatus(out []byte) *gitState {
+	state := &gitState{files: map[string]byte{}, dirs: map[string]byte{}}
+	entries := bytes.Split(out, []byte{0})
+	for i := 0; i < len(entries); i++ {
+		entry := string(entries[i])
+		if strings.HasPrefix(entry, "## ") {
+			state.parseBranch(entry[3:])
+			continue
+		}
+		if len(entry) < 4 {
+			continue
+		}
+		xy, path := entry[:2], entry[3:]
+		if xy[0] == 'R' || xy[0] == 'C' {
+			i++ // skips the original path
+		}
+		mark := gitMark(xy)
+		path = strings.TrimSuffix(path, "/")
+		state.files[path] = mark
+		if mark == gitIgnored {
+			continue
+		}
+		for dir := filepath.ToSlash(filepath.Dir(path)); dir != "."; dir = filepath.ToSlash(filepath.Dir(dir)) {
+			if gitRank(mark) <= gitRank(state.dirs[dir]) {
+				break
+			}
+			state.dirs[dir] = mark
+		}
+	}
+	return state
+}
+
+// parseBranch parses a line such as "main...origin/main [ahead 1, behind 2]".
+func (s *gitState) parseBranch(line string) {
+	if i := strings.Index(line, " ["); i >= 0 {
+		for _, field := range strings.Split(strings.Trim(line[i+2:], "]"), ", ") {
+			kv := strings.Fields(field)
+			if len(kv) != 2 {
+				continue
+			}
+			n, _ := strconv.Atoi(kv[1])
+			switch kv[0] {
+			case "ahead":
+				s.ahead = n
+			case "behind":
+				s.behind = n
+			}
+		}
+		line = line[:i]
+	}
+	line = strings.TrimPrefix(line, "No commits yet on ")
+	s.branch = strings.SplitN(line, "...", 2)[0]
+}
+
+func gitMark(xy string) byte {
+	x, y := xy[0], xy[1]
+	switch {
+	case xy == "??":
+		return gitUntracked
+	case xy == "!!":
+		return gitIgnored
+	case x == 'U' || y == 'U' || xy == "AA" || xy == "DD":
+		return gitConflicted
+	case y != ' ':
+		return gitModified
+	case x != ' ':
+		return gitStaged
+	}
+	return gitClean
+}
+
+// gitRank orders marks rolled up to directories.
+func gitRank(mark byte) int {
+	return strings.IndexByte("?SMU", mark) + 1
+}
+
+// lookup returns the mark of the path. Files in untracked or ignored
+// directories have the mark of the directory.
+func (s *gitState) lookup(path string, isDir bool) byte {
+	if mark, ok := s.files[path]; ok {
+		return mark
+	}
+	if isDir {
+		if mark, ok := s.dirs[path]; ok {
+			return mark
+		}
+	}
+	for dir := filepath.ToSlash(filepath.Dir(path)); dir != "."; dir = filepath.ToSlash(filepath.Dir(dir)) {
+		if mark := s.files[dir]; mark == gitUntracked || mark == gitIgnored {
+			return mark
+		}
+	}
+	return gitClean
+}
+
+// gitStatus returns the git status mark of the file, or 0 if not in a
+// working tree.
+func (f *FileStat) gitStatus() byte {
+	root, state := gitStatusOf(filepath.Dir(f.path))
+	if root == "" {
+		return 0
+	}
+	if state == nil || f.name == ".." {
+		return gitClean
+	}
+	rel, err := filepath.Rel(root, f.path)
+	if err != nil {
+		return gitClean
+	}
+	return state.lookup(filepath.ToSlash(rel), f.IsDir())
+}
+
+func gitLook(mark byte) tcell.Style {
+	d := tcell.StyleDefault
+	switch mark {
+	case gitUntracked:
+		return d.Foreground(tcell.ColorTeal)
+	case gitStaged:
+		return d.Foreground(tcell.ColorGreen).Bold(true)
+	case gitModified:
+		return d.Foreground(tcell.ColorRed).Bold(true)
+	case gitConflicted:
+		return d.Foreground(tcell.ColorFuchsia).Bold(true)
+	case gitIgnored:
+		return d.Foreground(tcell.ColorGray)
+	}
+	return d
+}
+
+// gitInfo returns the branch and ahead/behind counts of the working tree.
+func (d *Directory) gitInfo() string {
+	if d.IsRemote() {
+		return ""
+	}
+	root, state := gitStatusOf(d.Path)
+	if root == "" || state == nil || state.branch == "" {
+		return ""
+	}
+	s := " git:" + state.branch
+	if state.ahead > 0 {
+		s += fmt.Sprintf(" ↑%d", state.ahead)
+	}
+	if state.behind > 0 {
+		s += fmt.Sprintf(" ↓%d", state.behind)
+	}
+	return s
+}
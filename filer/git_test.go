@@ -0,0 +1,39 @@
+package filer
+
+import "testing"
+
+func TestParseGitStatus(t *testing.T) {
+	out := "## main...origin/main [ahead 2, behind 1]\x00" +
+		" M src/app/main.go\x00" +
+		"A  src/new.go\x00" +
+		"R  docs/b.md\x00docs/a.md\x00" +
+		"UU conflict.txt\x00" +
+		"?? tmp/\x00" +
+		"!! build/\x00"
+	state := parseGitStatus([]byte(out))
+	if state.branch != "main" || state.ahead != 2 || state.behind != 1 {
+		t.Errorf("branch=%q ahead=%d behind=%d, want main 2 1", state.branch, state.ahead, state.behind)
+	}
+	for _, d := range []struct {
+		path  string
+		isDir bool
+		mark  byte
+	}{
+		{"src/app/main.go", false, gitModified},
+		{"src/new.go", false, gitStaged},
+		{"docs/b.md", false, gitStaged},
+		{"docs/a.md", false, gitClean},
+		{"conflict.txt", false, gitConflicted},
+		{"tmp/x/y.txt", false, gitUntracked},
+		{"build", true, gitIgnored},
+		{"build/out.o", false, gitIgnored},
+		{"src", true, gitModified},
+		{"src/app", true, gitModified},
+		{"docs", true, gitStaged},
+		{"README.md", false, gitClean},
+	} {
+		if mark := state.lookup(d.path, d.isDir); mark != d.mark {
+			t.Errorf("lookup(%q)=%q, want %q", d.path, mark, d.mark)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package filer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/util"
+)
+
+const (
+	grepBinaryCheck = 8000    // bytes to check whether a binary file
+	grepMaxLine     = 1 << 20 // lines longer than this are not searched
+)
+
+var grepGitignore = true
+
+// SetGrepGitignore sets whether grep skips files ignored by .gitignore.
+func SetGrepGitignore(b bool) { grepGitignore = b }
+
+// grepMatch is a file matched by grep.
+type grepMatch struct {
+	count int    // number of matched lines
+	line  int    // the first matched line number
+	text  string // the first matched line
+}
+
+// grepReader lists files under the root whose contents match the regexp.
+type grepReader struct {
+	root      string
+	pattern   string
+	re        *regexp.Regexp
+	hiddens   bool
+	gitignore bool
+	mu        sync.Mutex // a canceled reading may remain while reading again
+	matches   map[string]*grepMatch
+}
+
+func (r *grepReader) String() string {
+	return fmt.Sprintf("Grep(내용검색):(%s)", r.pattern)
+}
+
+func (r *grepReader) Read(callback func(name string)) {
+	r.readCancel(callback, func() bool { return false })
+}
+
+// readCancel walks the root and stops when canceled.
+func (r *grepReader) readCancel(callback func(name string), canceled func() bool) {
+	r.mu.Lock()
+	r.matches = map[string]*grepMatch{}
+	r.mu.Unlock()
+	ignores := []ignoreRule{}
+	_ = filepath.Walk(r.root, func(path string, info os.FileInfo, err error) error {
+		if canceled() {
+			return io.EOF
+		}
+		if err != nil {
+			return nil
+		}
+		name, _ := filepath.Rel(r.root, path)
+		if info.IsDir() {
+			if path != r.root && (info.Name() == ".git" || !r.hiddens && isHidden(info.Name()) ||
+				r.gitignore && ignored(ignores, name, true)) {
+				return filepath.SkipDir
+			}
+			if r.gitignore {
+				ignores = append(ignores, loadGitignore(path, name)...)
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || !r.hiddens && isHidden(info.Name()) ||
+			r.gitignore && ignored(ignores, name, false) {
+			return nil
+		}
+		if m := grepFile(path, r.re); m != nil {
+			r.mu.Lock()
+			r.matches[name] = m
+			r.mu.Unlock()
+			callback(name)
+		}
+		return nil
+	})
+}
+
+func (r *grepReader) Stat(name string) *FileStat {
+	fs := newRelFileStat(r.root, name)
+	if fs != nil {
+		r.mu.Lock()
+		fs.grep = r.matches[name]
+		r.mu.Unlock()
+	}
+	return fs
+}
+
+// grepFile searches lines matching the regexp in the file. Returns nil if not
+// matched or a binary file.
+func grepFile(path string, re *regexp.Regexp) *grepMatch {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer fd.Close()
+
+	br := bufio.NewReader(fd)
+	if head, _ := br.Peek(grepBinaryCheck); bytes.IndexByte(head, 0) >= 0 {
+		return nil
+	}
+	var m *grepMatch
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(nil, grepMaxLine)
+	for n := 1; scanner.Scan(); n++ {
+		if !re.Match(scanner.Bytes()) {
+			continue
+		}
+		if m == nil {
+			m = &grepMatch{line: n, text: strings.TrimSpace(scanner.Text())}
+		}
+		m.count++
+	}
+	return m
+}
+
+// ignoreRule is a pattern of .gitignore in the base directory.
+type ignoreRule struct {
+	base     string
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool // matches the path from the base, otherwise the file name
+}
+
+// loadGitignore reads .gitignore in the directory named relatively to the root.
+func loadGitignore(dir, name string) []ignoreRule {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	if name == "." {
+		name = ""
+	}
+	rules := []ignoreRule{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: filepath.ToSlash(name)}
+		if strings.HasPrefix(line, "!") {
+			rule.negate, line = true, line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly, line = true, strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored, line = true, strings.TrimPrefix(line, "/")
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// ignored reports whether the path relative to the root is ignored by the
+// rules. Later rules override earlier ones.
+func ignored(rules []ignoreRule, name string, isDir bool) bool {
+	name = filepath.ToSlash(name)
+	ret := false
+	for _, rule := range rules {
+		rel := name
+		if rule.base != "" {
+			if !strings.HasPrefix(name, rule.base+"/") {
+				continue
+			}
+			rel = name[len(rule.base)+1:]
+		}
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		var ok bool
+		if rule.anchored {
+			ok = util.MatchPath(rule.pattern, rel)
+		} else {
+			ok, _ = filepath.Match(rule.pattern, filepath.Base(rel))
+		}
+		if ok {
+			ret = !rule.negate
+		}
+	}
+	return ret
+}
+
+// Grep lists files under the directory whose contents match the regexp.
+// Case insensitive when the pattern is lowercase only.
+func (d *Directory) Grep(pattern string) {
+	if d.IsRemote() {
+		message.Errorf("Grep is not supported in %s", d.Path)
+		return
+	}
+	expr := pattern
+	if expr == strings.ToLower(expr) {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		message.Error(err)
+		return
+	}
+	d.reader = &grepReader{root: d.Path, pattern: pattern, re: re, hiddens: d.showHiddens(), gitignore: grepGitignore}
+	d.read()
+}
+
+// IsGrep reports whether the directory lists files matched by grep.
+func (d *Directory) IsGrep() bool {
+	_, ok := d.reader.(*grepReader)
+	return ok
+}
+
+// GrepLine returns the first matched line number of the file listed by grep,
+// or 1 if not listed by grep.
+func (f *FileStat) GrepLine() int {
+	if f.grep == nil {
+		return 1
+	}
+	return f.grep.line
+}
+
+// IsGrep reports whether the file is listed by grep.
+func (f *FileStat) IsGrep() bool {
+	return f.grep != nil
+}
+
+// note returns the match count and the first matched line drawn after the name.
+func (f *FileStat) note() string {
+	if f.grep == nil {
+		return ""
+	}
+	return fmt.Sprintf("  [%d] %d: %s", f.grep.count, f.grep.line, f.grep.text)
+}
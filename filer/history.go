@@ -0,0 +1,96 @@
+package filer
+
+import (
+	"time"
+
+	"github.com/epainos/gofuli/message"
+)
+
+const (
+	maxVisited = 100 // visited paths kept in each directory
+	maxRecent  = 300 // recent paths kept across directories
+)
+
+// recentPaths is visited paths across directories from old to new.
+var recentPaths []string
+
+// visit adds the path to visited paths dropping paths forward, and adds the
+// old path at first to go back from the path.
+func (d *Directory) visit(old, path string) {
+	if d.VisitAt < len(d.Visited)-1 {
+		d.Visited = d.Visited[:d.VisitAt+1]
+	}
+	if len(d.Visited) == 0 && old != path {
+		d.Visited = append(d.Visited, old)
+	}
+	if len(d.Visited) == 0 || d.Visited[len(d.Visited)-1] != path {
+		d.Visited = append(d.Visited, path)
+	}
+	if len(d.Visited) > maxVisited {
+		d.Visited = d.Visited[len(d.Visited)-maxVisited:]
+	}
+	d.VisitAt = len(d.Visited) - 1
+	addRecent(path)
+}
+
+// addRecent adds the path to recent paths and to the frecency database.
+func addRecent(path string) {
+	addFrecency(path, time.Now())
+	recentPaths = append(removePath(recentPaths, path), path)
+	if len(recentPaths) > maxRecent {
+		recentPaths = recentPaths[len(recentPaths)-maxRecent:]
+	}
+}
+
+func removePath(paths []string, path string) []string {
+	for i, p := range paths {
+		if p == path {
+			return append(paths[:i:i], paths[i+1:]...)
+		}
+	}
+	return paths
+}
+
+// goVisited changes the directory to the visited path at the index.
+func (d *Directory) goVisited(i int) {
+	if i < 0 || i >= len(d.Visited) {
+		return
+	}
+	if d.chdir(d.Visited[i]) {
+		d.VisitAt = i
+		addRecent(d.Path)
+	}
+}
+
+// GoPreviousFolder goes back to the previous visited directory.
+func (d *Directory) GoPreviousFolder() {
+	if d.VisitAt <= 0 {
+		message.Info("No previous directory")
+		return
+	}
+	d.goVisited(d.VisitAt - 1)
+}
+
+// GoFowardFolder goes forward to the next visited directory.
+func (d *Directory) GoFowardFolder() {
+	if d.VisitAt >= len(d.Visited)-1 {
+		message.Info("No next directory")
+		return
+	}
+	d.goVisited(d.VisitAt + 1)
+}
+
+// VisitedPaths returns visited paths of the directory from old to new without
+// duplicates.
+func (d *Directory) VisitedPaths() []string {
+	paths := []string{}
+	for _, path := range d.Visited {
+		paths = append(removePath(paths, path), path)
+	}
+	return paths
+}
+
+// RecentPaths returns visited paths across directories from old to new.
+func RecentPaths() []string {
+	return append([]string{}, recentPaths...)
+}
@@ -0,0 +1,295 @@
+package filer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/epainos/gofuli/widget"
+)
+
+const (
+	loadWait     = 100 * time.Millisecond // waits loading before showing progressively
+	loadInterval = 100 * time.Millisecond // interval to wake up while loading
+)
+
+var wakeup = func() {}
+
+// ConfigWakeup sets a function to redraw directories loading in background.
+// The function is called from goroutines of loaders and must not block.
+func ConfigWakeup(f func()) {
+	wakeup = f
+}
+
+// loader reads a directory in background and keeps file stats until the
+// directory takes them on drawing.
+type loader struct {
+	path     string
+	reader   reader
+	replace  bool            // keeps the current list until loaded
+	async    bool            // still loading after waiting
+	target   string          // a name to set the cursor when loaded
+	marked   map[string]bool // paths marked before reading
+	mu       sync.Mutex
+	pending  []*FileStat
+	count    int
+	done     bool
+	canceled bool
+	finished chan bool
+}
+
+// cancelReader is a reader to stop reading when canceled.
+type cancelReader interface {
+	reader
+	readCancel(callback func(name string), canceled func() bool)
+}
+
+func (l *loader) run(r reader, stat func(name string) *FileStat) {
+	last := time.Now()
+	read := r.Read
+	if cr, ok := r.(cancelReader); ok {
+		read = func(callback func(string)) { cr.readCancel(callback, l.isCanceled) }
+	}
+	read(func(name string) {
+		if l.isCanceled() {
+			return
+		}
+		fs := stat(name)
+		if fs == nil {
+			return
+		}
+		l.mu.Lock()
+		if !l.canceled {
+			l.pending = append(l.pending, fs)
+			l.count++
+		}
+		l.mu.Unlock()
+		if time.Since(last) > loadInterval {
+			last = time.Now()
+			wakeup()
+		}
+	})
+	l.mu.Lock()
+	l.done = true
+	l.mu.Unlock()
+	close(l.finished)
+	wakeup()
+}
+
+func (l *loader) isCanceled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.canceled
+}
+
+func (l *loader) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.canceled = true
+	l.done = true
+}
+
+// load reads the directory in background. The list is replaced after loading
+// if it shows the same path, otherwise entries are shown as they arrive.
+func (d *Directory) load() {
+	if l := d.loader; l != nil && l.path == d.Path && l.reader == d.reader && !l.isCanceled() {
+		return // already loading
+	}
+	d.cancelLoad()
+
+	marked := make(map[string]bool, d.MarkCount())
+	for _, e := range d.List() {
+		if e.(*FileStat).IsMarked() {
+			marked[e.(*FileStat).Path()] = true
+		}
+	}
+	l := &loader{
+		path:     d.Path,
+		reader:   d.reader,
+		replace:  d.listed == d.Path,
+		marked:   marked,
+		finished: make(chan bool),
+	}
+	r, stat := d.reader, func(name string) *FileStat { return newRelFileStat(l.path, name) }
+	if sr, ok := r.(statReader); ok {
+		stat = sr.Stat
+	} else if _, ok := r.(defaultReader); ok {
+		r = defaultReader(d.Path) // independent of the working directory
+	}
+	hiddens := d.showHiddens()
+	go l.run(r, func(name string) *FileStat {
+		if !hiddens && isHidden(name) {
+			return nil
+		}
+		return stat(name)
+	})
+
+	d.loader = l
+	select {
+	case <-l.finished:
+	case <-time.After(loadWait):
+		l.async = true
+		if !l.replace {
+			d.ClearList()
+			d.AppendList(d.stat(".."))
+			d.listed = d.Path
+		}
+	}
+	d.drain()
+}
+
+// drain takes loaded file stats from the loader into the list.
+func (d *Directory) drain() {
+	l := d.loader
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	done, canceled := l.done, l.canceled
+	if l.replace && !done {
+		l.mu.Unlock()
+		return
+	}
+	pending := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+	if !done && len(pending) == 0 {
+		return
+	}
+
+	if done {
+		d.loader = nil
+		if l.replace && canceled {
+			return // keeps the current list
+		}
+	}
+	current := ""
+	if l.async && !d.IsEmpty() {
+		current = d.File().Name()
+	}
+	if !l.async || l.replace {
+		d.ClearList()
+	} else if len(pending) > 0 && len(d.List()) == 1 && d.List()[0].Name() == ".." {
+		d.ClearList()
+	}
+	for _, fs := range pending {
+		if l.marked[fs.Path()] {
+			fs.Mark()
+		}
+	}
+	if l.async && !l.replace && !d.Tree && d.finder == nil {
+		d.mergeList(pending) // the list is sorted as entries arrive
+	} else {
+		for _, fs := range pending {
+			d.AppendList(fs)
+		}
+		if done {
+			d.expand()
+			d.sortList()
+		}
+	}
+	if d.IsEmpty() {
+		d.AppendList(d.stat(".."))
+	}
+	d.listed = l.path
+
+	if !l.async {
+		return
+	}
+	if i := d.indexOf(l.target); l.target != "" && i >= 0 {
+		d.SetCursor(i)
+		d.SetOffsetCenteredCursor()
+		l.target = ""
+	} else if i := d.indexOf(current); i >= 0 {
+		d.SetCursor(i)
+	}
+}
+
+// mergeList sorts the file stats and merges them into the sorted list, not
+// to sort the whole list every time entries arrive.
+func (d *Directory) mergeList(stats []*FileStat) {
+	list := d.List()
+	batch := make([]widget.Drawer, len(stats))
+	for i, fs := range stats {
+		batch[i] = fs
+	}
+	d.SetList(batch)
+	sort.Sort(d)
+	pair := make([]widget.Drawer, 2)
+	d.SetList(pair)
+	merged := make([]widget.Drawer, 0, len(list)+len(batch))
+	i, j := 0, 0
+	for i < len(list) && j < len(batch) {
+		pair[0], pair[1] = batch[j], list[i]
+		if d.Less(0, 1) {
+			merged = append(merged, batch[j])
+			j++
+		} else {
+			merged = append(merged, list[i])
+			i++
+		}
+	}
+	merged = append(merged, list[i:]...)
+	d.SetList(append(merged, batch[j:]...))
+}
+
+// cancelLoad stops loading in background and keeps entries loaded so far.
+func (d *Directory) cancelLoad() {
+	if d.loader != nil {
+		d.loader.cancel()
+		d.drain()
+	}
+}
+
+// IsLoading reports whether the directory is loading in background.
+func (d *Directory) IsLoading() bool {
+	return d.loader != nil && d.loader.async
+}
+
+// cursorTo sets the cursor to the name now or when it is loaded.
+func (d *Directory) cursorTo(name string) {
+	if d.loader != nil && d.indexOf(name) < 0 {
+		d.loader.target = name
+	}
+	d.SetCursorByName(name)
+	d.SetOffsetCenteredCursor()
+}
+
+// Select sets the cursor to the first name, and marks the names if more
+// than one, now or when they are loaded.
+func (d *Directory) Select(names ...string) {
+	if len(names) == 0 {
+		return
+	}
+	d.cursorTo(names[0])
+	if len(names) == 1 {
+		return
+	}
+	for _, name := range names {
+		if i := d.indexOf(name); i >= 0 {
+			d.List()[i].(*FileStat).Mark()
+		} else if d.loader != nil {
+			d.loader.marked[filepath.Join(d.loader.path, name)] = true
+		}
+	}
+}
+
+func (d *Directory) indexOf(name string) int {
+	for i, e := range d.List() {
+		if e.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *Directory) loadingStatus() string {
+	if !d.IsLoading() {
+		return ""
+	}
+	d.loader.mu.Lock()
+	defer d.loader.mu.Unlock()
+	return fmt.Sprintf(" loading %d…", d.loader.count)
+}
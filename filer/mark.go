@@ -0,0 +1,167 @@
+package filer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/epainos/gofuli/message"
+)
+
+// visual is the range marking from the anchor to the cursor.
+type visual struct {
+	anchor string          // the file name where the range starts
+	marked map[string]bool // paths marked before the range
+}
+
+// markBy marks or unmarks files matching and returns the number of them.
+func (d *Directory) markBy(match func(f *FileStat) bool, mark bool) int {
+	n := 0
+	for _, e := range d.List() {
+		f := e.(*FileStat)
+		if f.Name() == ".." || !match(f) {
+			continue
+		}
+		if mark {
+			f.Mark()
+		} else {
+			f.Markoff()
+		}
+		n++
+	}
+	return n
+}
+
+func markedMessage(n int, mark bool) {
+	if mark {
+		message.Infof("Marked %d files", n)
+	} else {
+		message.Infof("Unmarked %d files", n)
+	}
+}
+
+// MarkGlob marks or unmarks files whose base names match the glob pattern.
+func (d *Directory) MarkGlob(pattern string, mark bool) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return err
+	}
+	n := d.markBy(func(f *FileStat) bool {
+		ok, _ := filepath.Match(pattern, filepath.Base(f.Name()))
+		return ok
+	}, mark)
+	markedMessage(n, mark)
+	return nil
+}
+
+// MarkRegexp marks or unmarks files whose names match the regexp. Case
+// insensitive when the regexp is lowercase only.
+func (d *Directory) MarkRegexp(expr string, mark bool) error {
+	if expr == strings.ToLower(expr) {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	n := d.markBy(func(f *FileStat) bool { return re.MatchString(f.Name()) }, mark)
+	markedMessage(n, mark)
+	return nil
+}
+
+// MarkQuery marks or unmarks files matching the query of the find mode such as
+// "mtime<7d", "size>10M" or "ext:jpg,png".
+func (d *Directory) MarkQuery(expr string, mark bool) error {
+	q, _, err := parseQuery(expr, time.Now())
+	if err != nil {
+		return err
+	}
+	n := d.markBy(func(f *FileStat) bool { return q(f.Name(), f.FileInfo) }, mark)
+	markedMessage(n, mark)
+	return nil
+}
+
+// MarkSameExt marks files with the same extension as the file on the cursor.
+func (d *Directory) MarkSameExt() {
+	ext := d.File().Ext()
+	if ext == "" {
+		message.Errorf("No extension of %s", d.File().Name())
+		return
+	}
+	n := d.markBy(func(f *FileStat) bool { return f.Ext() == ext }, true)
+	markedMessage(n, true)
+}
+
+// ToggleVisual starts or ends the visual mode marking files between the
+// anchor and the cursor as the cursor moves.
+func (d *Directory) ToggleVisual() {
+	if d.visual != nil {
+		d.visual = nil
+		return
+	}
+	marked := map[string]bool{}
+	for _, e := range d.List() {
+		if f := e.(*FileStat); f.IsMarked() {
+			marked[f.Path()] = true
+		}
+	}
+	d.visual = &visual{d.File().Name(), marked}
+	d.syncVisual()
+}
+
+// IsVisual reports whether the visual mode.
+func (d *Directory) IsVisual() bool {
+	return d.visual != nil
+}
+
+// syncVisual marks files in the range, and ends the visual mode if the anchor
+// disappears from the list.
+func (d *Directory) syncVisual() {
+	if d.visual == nil {
+		return
+	}
+	anchor := d.indexOf(d.visual.anchor)
+	if anchor < 0 {
+		d.visual = nil
+		return
+	}
+	from, to := anchor, d.Cursor()
+	if from > to {
+		from, to = to, from
+	}
+	for i, e := range d.List() {
+		f := e.(*FileStat)
+		if f.Name() != ".." && (from <= i && i <= to || d.visual.marked[f.Path()]) {
+			f.Mark()
+		} else {
+			f.Markoff()
+		}
+	}
+}
+
+// saveMarks saves paths of marked files to restore.
+func (d *Directory) saveMarks() {
+	marks := []string{}
+	for _, e := range d.List() {
+		if f := e.(*FileStat); f.IsMarked() {
+			marks = append(marks, f.Path())
+		}
+	}
+	if len(marks) > 0 {
+		d.lastMarks = marks
+	}
+}
+
+// RestoreMarks marks files again which were marked before the last clearing.
+func (d *Directory) RestoreMarks() {
+	if len(d.lastMarks) == 0 {
+		message.Info("No marks to restore")
+		return
+	}
+	paths := make(map[string]bool, len(d.lastMarks))
+	for _, path := range d.lastMarks {
+		paths[path] = true
+	}
+	n := d.markBy(func(f *FileStat) bool { return paths[f.Path()] }, true)
+	message.Infof("Restored %d marks", n)
+}
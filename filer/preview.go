@@ -0,0 +1,303 @@
+package filer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/epainos/gofuli/look"
+	"github.com/epainos/gofuli/util"
+	"github.com/epainos/gofuli/widget"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	previewDelay    = 100 * time.Millisecond // waits the cursor stopping before reading
+	previewBytes    = 64 * 1024              // reads the head of large files
+	previewHexBytes = 512                    // dumps the head of binary files
+	previewTabWidth = 4
+)
+
+var previewStyle = "monokai"
+
+// SetPreviewStyle sets the chroma style name to highlight texts in the preview.
+func SetPreviewStyle(name string) { previewStyle = name }
+
+// previewSpan is a text drawn in the style.
+type previewSpan struct {
+	text  string
+	style tcell.Style
+}
+
+// preview is a window showing contents of the cursor file. Contents are read
+// in background after the cursor stops.
+type preview struct {
+	*widget.Window
+	path  string // the cursor file path
+	timer *time.Timer
+
+	mu     sync.Mutex
+	want   string // the path to read
+	loaded string // the path of lines
+	lines  [][]previewSpan
+	info   string
+}
+
+func newPreview() *preview {
+	return &preview{Window: widget.NewWindow(0, 0, 0, 0)}
+}
+
+// update reads the path after the delay unless the cursor moves again.
+func (p *preview) update(path string, rows int, hiddens bool) {
+	if path == p.path {
+		return
+	}
+	p.path = path
+	p.mu.Lock()
+	p.want = path
+	p.mu.Unlock()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timer = time.AfterFunc(previewDelay, func() {
+		p.mu.Lock()
+		stale := p.want != path
+		p.mu.Unlock()
+		if stale {
+			return
+		}
+		lines, info := readPreview(path, rows, hiddens)
+		p.mu.Lock()
+		if p.want == path {
+			p.loaded, p.lines, p.info = path, lines, info
+		}
+		p.mu.Unlock()
+		wakeup()
+	})
+}
+
+func (p *preview) draw(d *Directory) {
+	p.SetBorderStyle(borderStyle)
+	p.Border()
+	rows := p.Height() - 2
+	if d.IsEmpty() || d.IsRemote() {
+		p.update("", rows, false)
+	} else {
+		p.update(d.File().Path(), rows, d.showHiddens())
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	x, y := p.LeftTop()
+	width := p.Width() - 1
+	shift := 0
+	if p.BorderStyle() == widget.AllBorder {
+		shift++
+		width--
+	}
+	if p.path != "" {
+		widget.SetCells(x+shift, y, runewidth.Truncate(filepath.Base(p.path), width, "~"), look.Title())
+	}
+	if p.loaded != p.want {
+		x, y := p.LeftBottom()
+		widget.SetCells(x+shift, y, "loading…", look.Default())
+		return
+	}
+	for i, spans := range p.lines {
+		if i >= rows {
+			break
+		}
+		col := 0
+		for _, span := range spans {
+			if col >= width {
+				break
+			}
+			s := runewidth.Truncate(span.text, width-col, "")
+			widget.SetCells(x+shift+col, y+1+i, s, span.style)
+			col += runewidth.StringWidth(s)
+		}
+	}
+	x, y = p.LeftBottom()
+	widget.SetCells(x+shift, y, runewidth.Truncate(p.info, width, "~"), look.Default())
+}
+
+// readPreview returns lines of the directory listing, the hexdump header of
+// a binary file, or the highlighted head of a text file.
+func readPreview(path string, rows int, hiddens bool) ([][]previewSpan, string) {
+	if path == "" {
+		return nil, ""
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return plainLines(err.Error()), ""
+	}
+	if fi.IsDir() {
+		return previewDir(path, rows, hiddens)
+	}
+	if !fi.Mode().IsRegular() { // reading a pipe or a device may block
+		return plainLines(fi.Mode().String()), fileType(fi.Mode())
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return plainLines(err.Error()), ""
+	}
+	defer file.Close()
+	buf := make([]byte, previewBytes)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return plainLines(err.Error()), ""
+	}
+	buf = buf[:n]
+	size := util.FormatSize(fi.Size())
+	if isBinary(buf) {
+		if len(buf) > previewHexBytes {
+			buf = buf[:previewHexBytes]
+		}
+		return plainLines(hex.Dump(buf)), "binary " + size
+	}
+	text, count := headLines(string(buf), rows)
+	info := fmt.Sprintf("%d lines %s", count, size)
+	if int64(n) < fi.Size() {
+		info = fmt.Sprintf("head %s of %s", util.FormatSize(int64(n)), size)
+	}
+	return highlight(filepath.Base(path), text), info
+}
+
+func previewDir(path string, rows int, hiddens bool) ([][]previewSpan, string) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return plainLines(err.Error()), ""
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].IsDir() && !entries[j].IsDir() })
+	lines := [][]previewSpan{}
+	count := 0
+	for _, e := range entries {
+		if !hiddens && isHidden(e.Name()) {
+			continue
+		}
+		count++
+		if len(lines) >= rows {
+			continue
+		}
+		if e.IsDir() {
+			lines = append(lines, []previewSpan{{e.Name() + "/", look.Directory()}})
+		} else {
+			lines = append(lines, []previewSpan{{e.Name(), look.Default()}})
+		}
+	}
+	return lines, fmt.Sprintf("%d files", count)
+}
+
+// fileType returns the type name of a file not regular nor a directory.
+func fileType(mode os.FileMode) string {
+	switch {
+	case mode&os.ModeNamedPipe != 0:
+		return "named pipe"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	case mode&os.ModeCharDevice != 0:
+		return "character device"
+	case mode&os.ModeDevice != 0:
+		return "device"
+	}
+	return "irregular file"
+}
+
+// isBinary reports whether the head of a file has a null byte or is not
+// UTF-8 except a rune cut at the end.
+func isBinary(buf []byte) bool {
+	if bytes.IndexByte(buf, 0) >= 0 {
+		return true
+	}
+	for i := 0; i < utf8.UTFMax && len(buf) > 0 && !utf8.Valid(buf); i++ {
+		buf = buf[:len(buf)-1]
+	}
+	return !utf8.Valid(buf)
+}
+
+// headLines returns the text of the first lines and the number of lines in
+// the text.
+func headLines(text string, rows int) (string, int) {
+	count := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		count++
+	}
+	i := 0
+	for n := 0; n < rows; n++ {
+		j := strings.IndexByte(text[i:], '\n')
+		if j < 0 {
+			return text, count
+		}
+		i += j + 1
+	}
+	return text[:i], count
+}
+
+func plainLines(text string) [][]previewSpan {
+	lines := [][]previewSpan{}
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		lines = append(lines, []previewSpan{{sanitize(line), look.Default()}})
+	}
+	return lines
+}
+
+// highlight returns lines of the text colored by the lexer of the file name
+// or the contents.
+func highlight(name, text string) [][]previewSpan {
+	lexer := lexers.Match(name)
+	if lexer == nil {
+		lexer = lexers.Analyse(text)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, text)
+	if err != nil {
+		return plainLines(text)
+	}
+	style := styles.Get(previewStyle)
+	lines := [][]previewSpan{}
+	for _, tokens := range chroma.SplitTokensIntoLines(it.Tokens()) {
+		spans := []previewSpan{}
+		for _, t := range tokens {
+			if s := sanitize(strings.TrimRight(t.Value, "\r\n")); s != "" {
+				spans = append(spans, previewSpan{s, tokenStyle(style.Get(t.Type))})
+			}
+		}
+		lines = append(lines, spans)
+	}
+	return lines
+}
+
+func tokenStyle(e chroma.StyleEntry) tcell.Style {
+	style := look.Default()
+	if e.Colour.IsSet() {
+		style = style.Foreground(tcell.NewRGBColor(int32(e.Colour.Red()), int32(e.Colour.Green()), int32(e.Colour.Blue())))
+	}
+	return style.Bold(e.Bold == chroma.Yes).Italic(e.Italic == chroma.Yes).Underline(e.Underline == chroma.Yes)
+}
+
+// sanitize expands tabs and replaces control characters not to break the
+// screen.
+func sanitize(s string) string {
+	s = strings.ReplaceAll(s, "\t", strings.Repeat(" ", previewTabWidth))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return '.'
+		}
+		return r
+	}, s)
+}
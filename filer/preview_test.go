@@ -0,0 +1,96 @@
+package filer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	for _, d := range []struct {
+		in  string
+		out bool
+	}{
+		{"hello\n", false},
+		{"한글", false},
+		{"한글"[:4], false}, // cut in the middle of a rune
+		{"a\x00b", true},
+		{"\xff\xfe\xfd\xfc\xfb", true},
+	} {
+		if out := isBinary([]byte(d.in)); out != d.out {
+			t.Errorf("isBinary(%q)=%v, want %v", d.in, out, d.out)
+		}
+	}
+}
+
+func TestHeadLines(t *testing.T) {
+	for _, d := range []struct {
+		in    string
+		rows  int
+		out   string
+		count int
+	}{
+		{"a\nb\nc\n", 2, "a\nb\n", 3},
+		{"a\nb\nc", 5, "a\nb\nc", 3},
+		{"", 3, "", 0},
+	} {
+		out, count := headLines(d.in, d.rows)
+		if out != d.out || count != d.count {
+			t.Errorf("headLines(%q, %d)=%q, %d, want %q, %d", d.in, d.rows, out, count, d.out, d.count)
+		}
+	}
+}
+
+func TestReadPreview(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"main.go":  "package main\n\nfunc main() {\n\tprintln(1)\n}\n",
+		"data.bin": "\x00\x01\x02",
+		"big.txt":  strings.Repeat("line\n", previewBytes/5+10),
+		".hidden":  "",
+	}
+	for name, text := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []struct {
+		name  string
+		first string
+		info  string
+	}{
+		{"main.go", "package main", "5 lines 42"},
+		{"data.bin", "00000000  00 01 02", "binary 3"},
+		{"big.txt", "line", "head 64.0k of 64.0k"},
+		{"", "sub/", "4 files"},
+		{"/dev/null", "Dc", "character device"}, // not read blocking
+	} {
+		path := d.name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, d.name)
+		} else if runtime.GOOS == "windows" {
+			continue
+		}
+		lines, info := readPreview(path, 10, false)
+		first := ""
+		if len(lines) > 0 {
+			for _, span := range lines[0] {
+				first += span.text
+			}
+		}
+		if !strings.HasPrefix(first, d.first) || info != d.info {
+			t.Errorf("readPreview(%q)=%q, %q, want %q, %q", d.name, first, info, d.first, d.info)
+		}
+	}
+}
@@ -0,0 +1,475 @@
+package filer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var findDepth = 0
+
+// SetFindDepth sets the default depth to walk for finding files, unlimited if
+// zero. The query term depth:N overrides it.
+func SetFindDepth(depth int) { findDepth = depth }
+
+// query reports whether the file named relatively to the root matches.
+type query func(name string, fi os.FileInfo) bool
+
+// findReader lists files under the root matching the query.
+type findReader struct {
+	root    string
+	expr    string
+	q       query
+	depth   int
+	hiddens bool
+}
+
+func (r *findReader) String() string {
+	return fmt.Sprintf("Find(속성검색):(%s)", r.expr)
+}
+
+func (r *findReader) Read(callback func(name string)) {
+	r.readCancel(callback, func() bool { return false })
+}
+
+func (r *findReader) readCancel(callback func(name string), canceled func() bool) {
+	_ = filepath.Walk(r.root, func(path string, info os.FileInfo, err error) error {
+		if canceled() {
+			return io.EOF
+		}
+		if err != nil || path == r.root {
+			return nil
+		}
+		name, _ := filepath.Rel(r.root, path)
+		if !r.hiddens && isHidden(info.Name()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if r.q(name, info) {
+			callback(name)
+		}
+		if info.IsDir() && r.depth > 0 && strings.Count(name, string(filepath.Separator))+1 >= r.depth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// Find lists files under the directory matching the query such as
+// "size>10M and not ext:log". Terms are size, mtime, type, ext, perm, name and
+// depth, and combined with and, or, not and parentheses.
+func (d *Directory) Find(expr string) error {
+	if d.IsRemote() {
+		return fmt.Errorf("find is not supported in %s", d.Path)
+	}
+	q, depth, err := parseQuery(expr, time.Now())
+	if err != nil {
+		return err
+	}
+	if depth < 0 {
+		depth = findDepth
+	}
+	d.reader = &findReader{d.Path, expr, q, depth, d.showHiddens()}
+	d.read()
+	return nil
+}
+
+// IsFind reports whether the directory lists files found by the query.
+func (d *Directory) IsFind() bool {
+	_, ok := d.reader.(*findReader)
+	return ok
+}
+
+// parseQuery compiles the query expression. Returns the depth of the term
+// depth:N or -1 if not specified.
+func parseQuery(expr string, now time.Time) (query, int, error) {
+	tokens, err := tokenizeQuery(expr)
+	if err != nil {
+		return nil, 0, err
+	}
+	p := &queryParser{tokens: tokens, now: now, depth: -1}
+	if len(p.tokens) == 0 {
+		return nil, 0, fmt.Errorf("empty query")
+	}
+	q, err := p.or()
+	if err != nil {
+		return nil, 0, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, 0, fmt.Errorf("unexpected %q in query", p.tokens[p.pos])
+	}
+	if q == nil { // only depth:N
+		q = func(string, os.FileInfo) bool { return true }
+	}
+	return q, p.depth, nil
+}
+
+var queryOps = []string{">=", "<=", "!=", ">", "<", "=", ":", "~"}
+
+// tokenizeQuery splits the expression by spaces and parentheses. A quoted
+// value is a token with spaces and parentheses, and a regexp after ~ ends
+// at a space or ) not closing its group.
+func tokenizeQuery(expr string) ([]string, error) {
+	tokens := []string{}
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' }
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case isSpace(c):
+			i++
+			continue
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+			continue
+		}
+		j := i
+		for j < len(expr) && (expr[j] >= 'a' && expr[j] <= 'z' || expr[j] >= 'A' && expr[j] <= 'Z') {
+			j++
+		}
+		op := ""
+		for _, o := range queryOps {
+			if j > i && strings.HasPrefix(expr[j:], o) {
+				op = o
+				break
+			}
+		}
+		j += len(op)
+		switch {
+		case op != "" && j < len(expr) && expr[j] == '"':
+			k := strings.IndexByte(expr[j+1:], '"')
+			if k < 0 {
+				return nil, fmt.Errorf("unterminated quote in query")
+			}
+			tokens = append(tokens, expr[i:j]+expr[j+1:j+1+k])
+			i = j + k + 2
+			continue
+		case op == "~":
+			for depth := 0; j < len(expr) && !isSpace(expr[j]); j++ {
+				if expr[j] == '\\' {
+					j++
+				} else if expr[j] == '(' {
+					depth++
+				} else if expr[j] == ')' {
+					if depth == 0 {
+						break
+					}
+					depth--
+				}
+			}
+		default:
+			for j < len(expr) && !isSpace(expr[j]) && expr[j] != '(' && expr[j] != ')' {
+				j++
+			}
+		}
+		if j > len(expr) {
+			j = len(expr) // a trailing backslash
+		}
+		tokens = append(tokens, expr[i:j])
+		i = j
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []string
+	pos    int
+	now    time.Time
+	depth  int
+	depths int // the number of depth terms parsed
+}
+
+func (p *queryParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return strings.ToLower(p.tokens[p.pos])
+	}
+	return ""
+}
+
+func (p *queryParser) or() (query, error) {
+	depths := p.depths
+	q, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" {
+		p.pos++
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		if p.depths != depths {
+			return nil, fmt.Errorf("depth cannot be combined by or")
+		}
+		q = orQuery(q, r)
+	}
+	return q, nil
+}
+
+// and parses terms joined by "and" or just spaces.
+func (p *queryParser) and() (query, error) {
+	q, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case "and":
+			p.pos++
+		case "", "or", ")":
+			return q, nil
+		}
+		r, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		q = andQuery(q, r)
+	}
+}
+
+func (p *queryParser) not() (query, error) {
+	switch p.peek() {
+	case "":
+		return nil, fmt.Errorf("unexpected end of query")
+	case "not":
+		p.pos++
+		depths := p.depths
+		q, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		if p.depths != depths {
+			return nil, fmt.Errorf("depth cannot be negated by not")
+		}
+		return func(name string, fi os.FileInfo) bool { return !q(name, fi) }, nil
+	case "(":
+		p.pos++
+		q, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ) in query")
+		}
+		p.pos++
+		return q, nil
+	}
+	term := p.tokens[p.pos]
+	p.pos++
+	return p.term(term)
+}
+
+// andQuery combines queries, where nil is no condition by depth:N.
+func andQuery(q, r query) query {
+	if q == nil {
+		return r
+	} else if r == nil {
+		return q
+	}
+	return func(name string, fi os.FileInfo) bool { return q(name, fi) && r(name, fi) }
+}
+
+func orQuery(q, r query) query {
+	return func(name string, fi os.FileInfo) bool { return q(name, fi) || r(name, fi) }
+}
+
+var termRegexp = regexp.MustCompile(`^([a-zA-Z]+)(>=|<=|!=|>|<|=|:|~)(.+)$`)
+
+func (p *queryParser) term(s string) (query, error) {
+	m := termRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("invalid query term %q", s)
+	}
+	field, op, value := strings.ToLower(m[1]), m[2], m[3]
+	switch field {
+	case "size":
+		size, err := parseSize(value)
+		if err != nil {
+			return nil, err
+		}
+		cmp, err := compareOp(op)
+		if err != nil {
+			return nil, err
+		}
+		return func(name string, fi os.FileInfo) bool { return cmp(compareInt(fi.Size(), size)) }, nil
+	case "mtime":
+		t, age, err := parseTime(value, p.now)
+		if err != nil {
+			return nil, err
+		}
+		cmp, err := compareOp(op)
+		if err != nil {
+			return nil, err
+		}
+		return func(name string, fi os.FileInfo) bool {
+			c := compareInt(fi.ModTime().Unix(), t.Unix())
+			if age { // mtime<7d means newer than 7 days ago
+				c = -c
+			}
+			return cmp(c)
+		}, nil
+	case "type":
+		if op != ":" && op != "=" {
+			return nil, fmt.Errorf("invalid operator %q for type", op)
+		}
+		return typeQuery(value)
+	case "ext":
+		if op != ":" && op != "=" {
+			return nil, fmt.Errorf("invalid operator %q for ext", op)
+		}
+		exts := map[string]bool{}
+		for _, ext := range strings.Split(strings.ToLower(value), ",") {
+			exts["."+strings.TrimPrefix(ext, ".")] = true
+		}
+		return func(name string, fi os.FileInfo) bool {
+			return !fi.IsDir() && exts[strings.ToLower(filepath.Ext(name))]
+		}, nil
+	case "perm":
+		if op != ":" && op != "=" {
+			return nil, fmt.Errorf("invalid operator %q for perm", op)
+		}
+		return permQuery(value)
+	case "name":
+		switch op {
+		case "~":
+			if value == strings.ToLower(value) {
+				value = "(?i)" + value
+			}
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, err
+			}
+			return func(name string, fi os.FileInfo) bool { return re.MatchString(fi.Name()) }, nil
+		case ":", "=":
+			if _, err := filepath.Match(value, ""); err != nil {
+				return nil, err
+			}
+			return func(name string, fi os.FileInfo) bool {
+				ok, _ := filepath.Match(value, fi.Name())
+				return ok
+			}, nil
+		}
+		return nil, fmt.Errorf("invalid operator %q for name", op)
+	case "depth":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || op != ":" && op != "=" {
+			return nil, fmt.Errorf("invalid depth %q", s)
+		}
+		p.depth = n
+		p.depths++
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown query field %q", m[1])
+}
+
+func compareOp(op string) (func(c int) bool, error) {
+	switch op {
+	case ">":
+		return func(c int) bool { return c > 0 }, nil
+	case "<":
+		return func(c int) bool { return c < 0 }, nil
+	case ">=":
+		return func(c int) bool { return c >= 0 }, nil
+	case "<=":
+		return func(c int) bool { return c <= 0 }, nil
+	case "=", ":":
+		return func(c int) bool { return c == 0 }, nil
+	case "!=":
+		return func(c int) bool { return c != 0 }, nil
+	}
+	return nil, fmt.Errorf("invalid operator %q", op)
+}
+
+// parseSize parses a size such as 100, 10K, 1.5M and 2GB by 1024 bytes units.
+func parseSize(s string) (int64, error) {
+	num := strings.TrimSuffix(strings.ToUpper(s), "B")
+	unit := int64(1)
+	if n := len(num); n > 0 {
+		if i := strings.IndexByte("KMGTP", num[n-1]); i >= 0 {
+			unit = 1 << (10 * uint(i+1))
+			num = num[:n-1]
+		}
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(f * float64(unit)), nil
+}
+
+// parseTime parses an age such as 30m, 12h, 7d, 2w and 1y, or a date such as
+// 2024-01-31. Reports true if the age.
+func parseTime(s string, now time.Time) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, false, nil
+	}
+	units := map[byte]time.Duration{
+		's': time.Second,
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+		'y': 365 * 24 * time.Hour,
+	}
+	n := len(s)
+	unit, ok := units[s[n-1]]
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("invalid time %q", s)
+	}
+	f, err := strconv.ParseFloat(s[:n-1], 64)
+	if err != nil || f < 0 {
+		return time.Time{}, false, fmt.Errorf("invalid time %q", s)
+	}
+	return now.Add(-time.Duration(f * float64(unit))), true, nil
+}
+
+func typeQuery(value string) (query, error) {
+	var match func(mode os.FileMode) bool
+	switch strings.ToLower(value) {
+	case "d", "dir", "directory":
+		match = func(mode os.FileMode) bool { return mode.IsDir() }
+	case "f", "file":
+		match = func(mode os.FileMode) bool { return mode.IsRegular() }
+	case "l", "link", "symlink":
+		match = func(mode os.FileMode) bool { return mode&os.ModeSymlink != 0 }
+	case "p", "pipe", "fifo":
+		match = func(mode os.FileMode) bool { return mode&os.ModeNamedPipe != 0 }
+	case "s", "socket":
+		match = func(mode os.FileMode) bool { return mode&os.ModeSocket != 0 }
+	default:
+		return nil, fmt.Errorf("unknown file type %q", value)
+	}
+	return func(name string, fi os.FileInfo) bool { return match(fi.Mode()) }, nil
+}
+
+// permQuery matches permissions such as +x (any executable bits), -w (no
+// writable bits) or 644 exactly.
+func permQuery(value string) (query, error) {
+	if n, err := strconv.ParseUint(value, 8, 32); err == nil {
+		return func(name string, fi os.FileInfo) bool { return fi.Mode().Perm() == os.FileMode(n) }, nil
+	}
+	if len(value) < 2 || value[0] != '+' && value[0] != '-' {
+		return nil, fmt.Errorf("invalid permission %q", value)
+	}
+	var bits os.FileMode
+	for _, c := range value[1:] {
+		switch c {
+		case 'r':
+			bits |= 0444
+		case 'w':
+			bits |= 0222
+		case 'x':
+			bits |= 0111
+		default:
+			return nil, fmt.Errorf("invalid permission %q", value)
+		}
+	}
+	has := value[0] == '+'
+	return func(name string, fi os.FileInfo) bool { return (fi.Mode().Perm()&bits != 0) == has }, nil
+}
@@ -0,0 +1,96 @@
+package filer
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+type testFileInfo struct {
+	name  string
+	size  int64
+	mode  os.FileMode
+	mtime time.Time
+}
+
+func (fi testFileInfo) Name() string       { return fi.name }
+func (fi testFileInfo) Size() int64        { return fi.size }
+func (fi testFileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi testFileInfo) ModTime() time.Time { return fi.mtime }
+func (fi testFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi testFileInfo) Sys() interface{}   { return nil }
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+	report := testFileInfo{"report.PDF", 20 << 20, 0644, now.Add(-2 * day)}
+	script := testFileInfo{"build.sh", 100, 0755, now.Add(-30 * day)}
+	dir := testFileInfo{"docs", 4096, os.ModeDir | 0755, now.Add(-1 * day)}
+	for _, d := range []struct {
+		expr   string
+		fi     testFileInfo
+		result bool
+	}{
+		{"size>10M", report, true},
+		{"size>10M", script, false},
+		{"size<=100", script, true},
+		{"mtime<7d", report, true},
+		{"mtime<7d", script, false},
+		{"mtime>1w", script, true},
+		{"mtime<2024-05-15", script, true},
+		{"type:dir", dir, true},
+		{"type:f", dir, false},
+		{"ext:pdf,docx", report, true},
+		{"ext:.sh", report, false},
+		{"perm:+x", script, true},
+		{"perm:+x", report, false},
+		{"perm:644", report, true},
+		{"name~^build", script, true},
+		{"name:*.sh", script, true},
+		{"ext:pdf or perm:+x", script, true},
+		{"ext:pdf and perm:+x", script, false},
+		{"ext:pdf perm:+x", report, false},
+		{"not type:dir", dir, false},
+		{"not (ext:pdf or type:dir) and size<1K", script, true},
+		{"depth:2 type:dir", dir, true},
+		{"name~^(build|report)\\.", script, true},
+		{"(name~(x|y) or type:dir)", dir, true},
+		{"(name~^(bu)+ild)", script, true},
+		{`name:"build.sh" or name:"my (1).txt"`, script, true},
+		{`name~"report pdf|^docs$"`, dir, true},
+	} {
+		q, _, err := parseQuery(d.expr, now)
+		if err != nil {
+			t.Errorf("parseQuery(%q) error %v", d.expr, err)
+			continue
+		}
+		if result := q(d.fi.name, d.fi); result != d.result {
+			t.Errorf("parseQuery(%q)(%q)=%v, want %v", d.expr, d.fi.name, result, d.result)
+		}
+	}
+
+	for _, expr := range []string{"", "size>", "size~10", "foo:bar", "(type:dir", "type:dir)", "perm:+z", "not", "perm>644", "not depth:3", "not (depth:3 type:f)", "depth:3 or type:f", `name:"x`} {
+		if _, _, err := parseQuery(expr, now); err == nil {
+			t.Errorf("parseQuery(%q) no error", expr)
+		}
+	}
+}
+
+func TestTokenizeQuery(t *testing.T) {
+	for _, d := range []struct {
+		in  string
+		out string
+	}{
+		{"(ext:go or type:dir)", "[( ext:go or type:dir )]"},
+		{"name~(a|b)c size>1K", "[name~(a|b)c size>1K]"},
+		{"(name~a(b))", "[( name~a(b) )]"},
+		{`name~\)x`, `[name~\)x]`},
+		{`name:"a (b) c" not`, "[name:a (b) c not]"},
+	} {
+		tokens, err := tokenizeQuery(d.in)
+		if out := fmt.Sprint(tokens); err != nil || out != d.out {
+			t.Errorf("tokenizeQuery(%q)=%s, %v, want %s", d.in, out, err, d.out)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package filer
+
+import (
+	"os"
+	"time"
+
+	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/s3"
+)
+
+// statReader is a reader for files not on the local file system.
+// The file stats are made from informations listed by the reader.
+type statReader interface {
+	reader
+	Stat(name string) *FileStat
+}
+
+// s3Reader lists a prefix of a bucket as a directory.
+type s3Reader struct {
+	path  string
+	infos map[string]os.FileInfo
+}
+
+func newS3Reader(path string) *s3Reader {
+	return &s3Reader{path, map[string]os.FileInfo{}}
+}
+
+func (r *s3Reader) String() string { return "" }
+
+func (r *s3Reader) Read(callback func(name string)) {
+	client, err := s3.Default()
+	if err != nil {
+		message.Error(err)
+		return
+	}
+	r.infos = map[string]os.FileInfo{}
+	add := func(fi *s3.ObjectInfo) {
+		r.infos[fi.Name()] = fi
+		callback(fi.Name())
+	}
+
+	bucket, key := s3.Split(r.path)
+	if bucket == "" {
+		buckets, err := client.ListBuckets()
+		if err != nil {
+			message.Error(err)
+			return
+		}
+		for _, b := range buckets {
+			add(b)
+		}
+		return
+	}
+	prefix := key
+	if prefix != "" {
+		prefix += "/"
+	}
+	if err := client.List(bucket, prefix, "/", add); err != nil {
+		message.Error(err)
+	}
+}
+
+func (r *s3Reader) Stat(name string) *FileStat {
+	path := s3.Clean(r.path + "/" + name)
+	if name == ".." {
+		return newFileStat(path, name, parentInfo{}, parentInfo{})
+	}
+	fi, ok := r.infos[name]
+	if !ok {
+		return nil
+	}
+	return newFileStat(path, name, fi, fi)
+}
+
+// parentInfo is the upper directory of a remote directory.
+type parentInfo struct{}
+
+func (parentInfo) Name() string       { return ".." }
+func (parentInfo) Size() int64        { return 0 }
+func (parentInfo) Mode() os.FileMode  { return os.ModeDir | 0755 }
+func (parentInfo) ModTime() time.Time { return time.Time{} }
+func (parentInfo) IsDir() bool        { return true }
+func (parentInfo) Sys() interface{}   { return nil }
+
+// readerFor returns the default reader for the path.
+func readerFor(path string) reader {
+	if s3.IsPath(path) {
+		return newS3Reader(path)
+	}
+	return defaultReader(".")
+}
+
+// isDefaultReader reports whether the reader lists a directory as it is.
+func isDefaultReader(r reader) bool {
+	switch r.(type) {
+	case defaultReader, *s3Reader:
+		return true
+	}
+	return false
+}
+
+// IsRemote reports whether the directory is on object storage.
+func (d *Directory) IsRemote() bool {
+	return s3.IsPath(d.Path)
+}
+
+// stat creates a file stat of the name in the directory by the reader.
+func (d *Directory) stat(name string) *FileStat {
+	if r, ok := d.reader.(statReader); ok {
+		return r.Stat(name)
+	}
+	return newRelFileStat(d.Path, name)
+}
@@ -0,0 +1,236 @@
+package filer
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/epainos/gofuli/util"
+)
+
+// sortKey is a field to compare files in the order.
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+func (k sortKey) String() string {
+	if k.desc {
+		return k.field + "[$]"
+	}
+	return k.field + "[^]"
+}
+
+// sortFields maps aliases to fields for sorting.
+var sortFields = map[string]string{
+	"name":       "Name",
+	"size":       "Size",
+	"time":       "Time",
+	"mtime":      "Time",
+	"ext":        "Ext",
+	"extension":  "Ext",
+	"natural":    "Natural",
+	"locale":     "Locale",
+	"owner":      "Owner",
+	"user":       "Owner",
+	"group":      "Group",
+	"perm":       "Perm",
+	"permission": "Perm",
+	"type":       "Type",
+	"atime":      "Atime",
+	"ctime":      "Ctime",
+	"length":     "Length",
+	"len":        "Length",
+}
+
+var sortKeysCache = map[sortType][]sortKey{}
+
+// sortKeys returns keys of a sort type such as "Ext[^],Size[$]".
+func sortKeys(typ sortType) []sortKey {
+	if keys, ok := sortKeysCache[typ]; ok {
+		return keys
+	}
+	keys, err := parseSortKeys(string(typ))
+	if err != nil {
+		keys = nil
+	}
+	sortKeysCache[typ] = keys
+	return keys
+}
+
+// parseSortKeys parses comma separated keys such as "ext, size desc" or
+// "Ext[^],Size[$]".
+func parseSortKeys(expr string) ([]sortKey, error) {
+	keys := []sortKey{}
+	for _, s := range strings.Split(expr, ",") {
+		fields := strings.Fields(s)
+		if len(fields) == 0 {
+			continue
+		}
+		name, desc := strings.ToLower(fields[0]), false
+		if strings.HasSuffix(name, "[$]") {
+			name, desc = strings.TrimSuffix(name, "[$]"), true
+		} else {
+			name = strings.TrimSuffix(name, "[^]")
+		}
+		if len(fields) > 1 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+			case "desc":
+				desc = true
+			default:
+				return nil, fmt.Errorf("invalid sort order %q", fields[1])
+			}
+		}
+		field, ok := sortFields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q", fields[0])
+		}
+		keys = append(keys, sortKey{field, desc})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no sort keys")
+	}
+	return keys, nil
+}
+
+// SortKeys sorts files by comma separated keys such as "ext, size desc".
+// Fields are name, size, time, ext, natural, locale, owner, group, perm,
+// type, atime, ctime and length.
+func (d *Directory) SortKeys(expr string) error {
+	keys, err := parseSortKeys(expr)
+	if err != nil {
+		return err
+	}
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = k.String()
+	}
+	d.sortBy(sortType(strings.Join(s, ",")))
+	return nil
+}
+
+// hasSortField reports whether the directory is sorted by the field.
+func (d *Directory) hasSortField(field string) bool {
+	for _, k := range sortKeys(d.Sort) {
+		if k.field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Directory) lessKeys(i, j int, keys []sortKey) bool {
+	f1 := d.List()[i].(*FileStat)
+	f2 := d.List()[j].(*FileStat)
+	for _, k := range keys {
+		c := compareField(k.field, f1, f2)
+		if c != 0 {
+			if k.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+	}
+	return f1.Name() < f2.Name()
+}
+
+func compareField(field string, f1, f2 *FileStat) int {
+	switch field {
+	case "Name":
+		return strings.Compare(f1.Name(), f2.Name())
+	case "Size":
+		return compareInt(f1.size(), f2.size())
+	case "Time":
+		return compareInt(f1.ModTime().Unix(), f2.ModTime().Unix())
+	case "Ext":
+		return strings.Compare(f1.Ext(), f2.Ext())
+	case "Natural":
+		if util.NaturalLess(f1.Name(), f2.Name()) {
+			return -1
+		} else if util.NaturalLess(f2.Name(), f1.Name()) {
+			return 1
+		}
+	case "Locale":
+		if collator == nil {
+			SetCollateLocale("")
+		}
+		return collator.CompareString(f1.Name(), f2.Name())
+	case "Owner":
+		u1, _ := fileOwner(f1.FileInfo)
+		u2, _ := fileOwner(f2.FileInfo)
+		return strings.Compare(userName(u1), userName(u2))
+	case "Group":
+		_, g1 := fileOwner(f1.FileInfo)
+		_, g2 := fileOwner(f2.FileInfo)
+		return strings.Compare(groupName(g1), groupName(g2))
+	case "Perm":
+		return compareInt(int64(f1.Mode().Perm()), int64(f2.Mode().Perm()))
+	case "Type":
+		return compareInt(typeRank(f1), typeRank(f2))
+	case "Atime":
+		a1, _ := fileTimes(f1.FileInfo)
+		a2, _ := fileTimes(f2.FileInfo)
+		return compareInt(a1.UnixNano(), a2.UnixNano())
+	case "Ctime":
+		_, c1 := fileTimes(f1.FileInfo)
+		_, c2 := fileTimes(f2.FileInfo)
+		return compareInt(c1.UnixNano(), c2.UnixNano())
+	case "Length":
+		return compareInt(int64(utf8.RuneCountInString(f1.Name())), int64(utf8.RuneCountInString(f2.Name())))
+	}
+	return 0
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// typeRank orders directories, symbolic links, regular files and others.
+func typeRank(f *FileStat) int64 {
+	switch mode := f.Mode(); {
+	case mode.IsDir():
+		return 0
+	case mode&os.ModeSymlink != 0:
+		return 1
+	case mode.IsRegular():
+		return 2
+	}
+	return 3
+}
+
+var userNames = map[string]string{}
+
+func userName(uid string) string {
+	if name, ok := userNames[uid]; ok {
+		return name
+	}
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	userNames[uid] = name
+	return name
+}
+
+var groupNames = map[string]string{}
+
+func groupName(gid string) string {
+	if name, ok := groupNames[gid]; ok {
+		return name
+	}
+	name := gid
+	if g, err := user.LookupGroupId(gid); err == nil {
+		name = g.Name
+	}
+	groupNames[gid] = name
+	return name
+}
@@ -0,0 +1,16 @@
+package filer
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileTimes returns the access time and the status change time of the file.
+func fileTimes(fi os.FileInfo) (time.Time, time.Time) {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fi.ModTime(), fi.ModTime()
+	}
+	return time.Unix(int64(stat.Atim.Sec), int64(stat.Atim.Nsec)), time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec))
+}
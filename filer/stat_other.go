@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package filer
+
+import (
+	"os"
+	"time"
+)
+
+// fileTimes returns the modified time for both because the access time and
+// the status change time differ in file stats on this system.
+func fileTimes(fi os.FileInfo) (time.Time, time.Time) {
+	return fi.ModTime(), fi.ModTime()
+}
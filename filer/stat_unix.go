@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package filer
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// fileOwner returns the user and group ids of the file.
+func fileOwner(fi os.FileInfo) (string, string) {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", ""
+	}
+	return strconv.FormatUint(uint64(stat.Uid), 10), strconv.FormatUint(uint64(stat.Gid), 10)
+}
@@ -0,0 +1,24 @@
+//go:build windows
+// +build windows
+
+package filer
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileOwner returns empty ids because the owner is not in file stats on windows.
+func fileOwner(fi os.FileInfo) (string, string) {
+	return "", ""
+}
+
+// fileTimes returns the access time and the creation time of the file.
+func fileTimes(fi os.FileInfo) (time.Time, time.Time) {
+	data, ok := fi.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return fi.ModTime(), fi.ModTime()
+	}
+	return time.Unix(0, data.LastAccessTime.Nanoseconds()), time.Unix(0, data.CreationTime.Nanoseconds())
+}
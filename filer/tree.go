@@ -0,0 +1,139 @@
+package filer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/widget"
+)
+
+// ToggleTree toggles the tree view expanding sub directories in place.
+func (d *Directory) ToggleTree() {
+	if d.IsRemote() {
+		message.Errorf("Tree view is not supported in %s", d.Path)
+		return
+	}
+	d.Tree = !d.Tree
+	name := d.File().Name()
+	if !d.Tree {
+		name = strings.SplitN(name, string(filepath.Separator), 2)[0]
+	}
+	d.read()
+	d.cursorTo(name)
+}
+
+// ToggleExpand expands or collapses the directory on the cursor in the tree view.
+func (d *Directory) ToggleExpand() {
+	if !d.Tree {
+		return
+	}
+	f := d.File()
+	if f.Name() == ".." || !f.IsDir() {
+		return
+	}
+	name := f.Name()
+	if d.Expanded[f.Path()] {
+		delete(d.Expanded, f.Path())
+	} else {
+		if d.Expanded == nil {
+			d.Expanded = map[string]bool{}
+		}
+		d.Expanded[f.Path()] = true
+		d.expand()
+	}
+	d.sortList()
+	d.cursorTo(name)
+}
+
+// expand appends files in expanded directories to the list recursively.
+func (d *Directory) expand() {
+	if !d.Tree {
+		return
+	}
+	loaded := map[string]bool{}
+	for _, e := range d.List() {
+		if dir := filepath.Dir(e.Name()); dir != "." {
+			loaded[dir] = true
+		}
+	}
+	for i := 0; i < len(d.List()); i++ {
+		f := d.List()[i].(*FileStat)
+		if f.Name() == ".." || !f.IsDir() || !d.Expanded[f.Path()] || loaded[f.Name()] {
+			continue
+		}
+		loaded[f.Name()] = true
+		d.AppendList(d.children(f)...)
+	}
+}
+
+// children creates file stats in the directory named relatively to the list.
+func (d *Directory) children(f *FileStat) []widget.Drawer {
+	fd, err := os.Open(f.Path())
+	if err != nil {
+		message.Error(err)
+		return nil
+	}
+	defer fd.Close()
+	names, err := fd.Readdirnames(-1)
+	if err != nil {
+		message.Error(err)
+	}
+	list := make([]widget.Drawer, 0, len(names))
+	for _, name := range names {
+		if !d.showHiddens() && strings.HasPrefix(name, ".") {
+			continue
+		}
+		if fs := newRelFileStat(d.Path, filepath.Join(f.Name(), name)); fs != nil {
+			fs.prefix = "" // drawn by indentation guides
+			list = append(list, fs)
+		}
+	}
+	return list
+}
+
+// sortList sorts files, within each level of expanded directories in the tree view.
+func (d *Directory) sortList() {
+	if d.finder != nil && d.finder.ranked() {
+		return // keeps the order of fuzzy scores
+	}
+	if !d.Tree || d.finder != nil || !isDefaultReader(d.reader) {
+		sort.Sort(d)
+		return
+	}
+	levels := map[string][]widget.Drawer{}
+	for _, e := range d.List() {
+		dir := filepath.Dir(e.Name())
+		levels[dir] = append(levels[dir], e)
+	}
+	list := make([]widget.Drawer, 0, len(d.List()))
+	var flatten func(dir, indent string)
+	flatten = func(dir, indent string) {
+		level := levels[dir]
+		d.SetList(level)
+		sort.Sort(d)
+		for i, e := range level {
+			f := e.(*FileStat)
+			f.indent = ""
+			child := indent + "│ "
+			if dir != "." {
+				if i == len(level)-1 {
+					f.indent = indent + "└ "
+					child = indent + "  "
+				} else {
+					f.indent = indent + "├ "
+				}
+			} else {
+				child = ""
+			}
+			list = append(list, f)
+			if f.IsDir() && f.Name() != ".." && d.Expanded[f.Path()] {
+				flatten(f.Name(), child)
+			}
+		}
+	}
+	flatten(".", "")
+	d.SetList(list)
+}
@@ -0,0 +1,206 @@
+package filer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/util"
+)
+
+// ViewSetting is view settings applied to directories matching the path.
+// Empty fields follow the settings of the pane or the global.
+type ViewSetting struct {
+	Path     string    `json:"path"` // a directory path or a glob pattern such as ~/Downloads/**
+	Sort     sortType  `json:"sort_kind,omitempty"`
+	Hiddens  *bool     `json:"show_hiddens,omitempty"`
+	Priority *bool     `json:"priority,omitempty"`
+	Stat     *StatView `json:"stat,omitempty"`
+	Filter   string    `json:"filter,omitempty"` // a glob pattern to list files
+}
+
+// StatView is file stat columns of view settings.
+type StatView struct {
+	Size       bool `json:"size"`
+	Permission bool `json:"permission"`
+	Time       bool `json:"time"`
+}
+
+var views = struct {
+	path     string
+	settings []*ViewSetting
+}{}
+
+// LoadViews loads view settings from the json file and saves changes to it.
+func LoadViews(path string) error {
+	views.path = path
+	data, err := ioutil.ReadFile(util.ExpandPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, &views.settings)
+}
+
+func saveViews() error {
+	if views.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(views.settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := util.ExpandPath(views.path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func isPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// viewFor merges view settings matching the path. Later patterns override
+// earlier ones, and the exact path overrides all patterns.
+func viewFor(path string) ViewSetting {
+	var view ViewSetting
+	merge := func(v *ViewSetting) {
+		if v.Sort != "" {
+			view.Sort = v.Sort
+		}
+		if v.Hiddens != nil {
+			view.Hiddens = v.Hiddens
+		}
+		if v.Priority != nil {
+			view.Priority = v.Priority
+		}
+		if v.Stat != nil {
+			view.Stat = v.Stat
+		}
+		if v.Filter != "" {
+			view.Filter = v.Filter
+		}
+	}
+	for _, v := range views.settings {
+		if isPattern(v.Path) && util.MatchPath(util.ExpandPath(v.Path), path) {
+			merge(v)
+		}
+	}
+	if v := exactView(path); v != nil {
+		merge(v)
+	}
+	view.Path = path
+	return view
+}
+
+func exactView(path string) *ViewSetting {
+	for _, v := range views.settings {
+		if !isPattern(v.Path) && filepath.Clean(util.ExpandPath(v.Path)) == path {
+			return v
+		}
+	}
+	return nil
+}
+
+// updateView changes the view settings of the exact path and saves them.
+func updateView(path string, update func(v *ViewSetting)) {
+	v := exactView(path)
+	if v == nil {
+		v = &ViewSetting{Path: util.AbbrPath(path)}
+		views.settings = append(views.settings, v)
+	}
+	update(v)
+	if err := saveViews(); err != nil {
+		message.Error(err)
+	}
+}
+
+// applyView applies view settings matching the directory path.
+func (d *Directory) applyView() {
+	d.view = ViewSetting{}
+	if !d.IsRemote() {
+		d.view = viewFor(d.Path)
+	}
+	if d.view.Sort != "" {
+		if d.paneSort == "" {
+			d.paneSort = d.Sort
+		}
+		d.Sort = d.view.Sort
+	} else if d.paneSort != "" {
+		d.Sort = d.paneSort
+		d.paneSort = ""
+	}
+	if d.view.Filter != "" {
+		d.reader = globPattern(d.view.Filter)
+	}
+}
+
+func (d *Directory) showHiddens() bool {
+	if d.view.Hiddens != nil {
+		return *d.view.Hiddens
+	}
+	return showHiddens
+}
+
+func (d *Directory) priorityDir() bool {
+	if d.view.Priority != nil {
+		return *d.view.Priority
+	}
+	return priorityDir
+}
+
+func (d *Directory) statView() fileStatView {
+	if s := d.view.Stat; s != nil {
+		return fileStatView{s.Size, s.Permission, s.Time}
+	}
+	return statView
+}
+
+// RememberView saves the current sort kind, hidden files, priority, stat
+// columns and glob filter as the view settings for the directory path.
+func (d *Directory) RememberView() {
+	if d.IsRemote() {
+		message.Errorf("View settings are not supported in %s", d.Path)
+		return
+	}
+	hiddens, priority, stat := d.showHiddens(), d.priorityDir(), d.statView()
+	filter := ""
+	if pattern, ok := d.reader.(globPattern); ok {
+		filter = string(pattern)
+	}
+	updateView(d.Path, func(v *ViewSetting) {
+		v.Sort = d.Sort
+		v.Hiddens = &hiddens
+		v.Priority = &priority
+		v.Stat = &StatView{stat.size, stat.permission, stat.time}
+		v.Filter = filter
+	})
+	d.view = viewFor(d.Path)
+	message.Infof("Remembered the view of %s", util.AbbrPath(d.Path))
+}
+
+// ForgetView removes the view settings saved for the directory path.
+func (d *Directory) ForgetView() {
+	exact := exactView(d.Path)
+	for i, v := range views.settings {
+		if v == exact {
+			views.settings = append(views.settings[:i], views.settings[i+1:]...)
+			if err := saveViews(); err != nil {
+				message.Error(err)
+			}
+			break
+		}
+	}
+	d.view = viewFor(d.Path)
+	message.Infof("Forgot the view of %s", util.AbbrPath(d.Path))
+}
@@ -0,0 +1,308 @@
+package filer
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	watchDelay    = 200 * time.Millisecond // quiet period to gather changes
+	watchMaxDelay = time.Second            // notify at least this often in bursts
+	watchMaxNames = 256                    // reload all instead of many files
+	watchInterval = time.Second            // interval for polling mtime
+)
+
+// Changes maps directory paths to changed file names.
+// A nil names means the directory needs to be reloaded entirely.
+type Changes map[string][]string
+
+type watchEntry struct {
+	mtime  time.Time
+	polled bool // true if not watched by the notification
+}
+
+// Watcher watches directories by file system notifications such as inotify
+// and polls modified times of directories the notification is unavailable.
+// Changes are notified after bursts of events settle down.
+type Watcher struct {
+	mu      sync.Mutex
+	fsw     *fsnotify.Watcher
+	entries map[string]*watchEntry
+	changes map[string]map[string]bool
+	timer   *time.Timer
+	first   time.Time
+	notify  func(Changes)
+	done    chan bool
+}
+
+// NewWatcher creates a new watcher calling notify with changes.
+// The notify is called from a goroutine of the watcher.
+func NewWatcher(notify func(Changes)) *Watcher {
+	w := &Watcher{
+		entries: map[string]*watchEntry{},
+		changes: map[string]map[string]bool{},
+		notify:  notify,
+		done:    make(chan bool),
+	}
+	if fsw, err := fsnotify.NewWatcher(); err == nil {
+		w.fsw = fsw
+		go w.receive()
+	}
+	go w.poll()
+	return w
+}
+
+// Watch sets directories to watch and unwatches others.
+func (w *Watcher) Watch(paths ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	watching := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		watching[path] = true
+		if _, ok := w.entries[path]; ok {
+			continue
+		}
+		entry := &watchEntry{polled: true}
+		if fi, err := os.Stat(path); err == nil {
+			entry.mtime = fi.ModTime()
+		}
+		if w.fsw != nil && w.fsw.Add(path) == nil {
+			entry.polled = false
+		}
+		w.entries[path] = entry
+	}
+	for path, entry := range w.entries {
+		if watching[path] {
+			continue
+		}
+		if !entry.polled {
+			_ = w.fsw.Remove(path)
+		}
+		delete(w.entries, path)
+		delete(w.changes, path)
+	}
+}
+
+// Close stops watching.
+func (w *Watcher) Close() {
+	close(w.done)
+	if w.fsw != nil {
+		w.fsw.Close()
+	}
+}
+
+func (w *Watcher) receive() {
+	for {
+		select {
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			w.change(filepath.Dir(ev.Name), filepath.Base(ev.Name))
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			if err == fsnotify.ErrEventOverflow {
+				w.mu.Lock()
+				paths := make([]string, 0, len(w.entries))
+				for path := range w.entries {
+					paths = append(paths, path)
+				}
+				w.mu.Unlock()
+				for _, path := range paths {
+					w.change(path, "")
+				}
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *Watcher) poll() {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.done:
+			return
+		}
+		w.mu.Lock()
+		var modified []string
+		for path, entry := range w.entries {
+			if !entry.polled {
+				continue
+			}
+			fi, err := os.Stat(path)
+			if err != nil || fi.ModTime().Equal(entry.mtime) {
+				continue
+			}
+			entry.mtime = fi.ModTime()
+			modified = append(modified, path)
+		}
+		w.mu.Unlock()
+		for _, path := range modified {
+			w.change(path, "")
+		}
+	}
+}
+
+// change records a changed name in the directory and schedules notifying.
+// An empty name means the whole directory changed.
+func (w *Watcher) change(dir, name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.entries[dir]; !ok {
+		return
+	}
+	names, ok := w.changes[dir]
+	switch {
+	case ok && names == nil: // already reloading entirely
+	case name == "" || len(names) >= watchMaxNames:
+		w.changes[dir] = nil
+	default:
+		if names == nil {
+			names = map[string]bool{}
+			w.changes[dir] = names
+		}
+		names[name] = true
+	}
+
+	now := time.Now()
+	if w.timer == nil {
+		w.first = now
+		w.timer = time.AfterFunc(watchDelay, w.flush)
+	} else if now.Sub(w.first) < watchMaxDelay {
+		w.timer.Reset(watchDelay)
+	}
+}
+
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	w.timer = nil
+	if len(w.changes) == 0 {
+		w.mu.Unlock()
+		return
+	}
+	changes := make(Changes, len(w.changes))
+	for dir, names := range w.changes {
+		if names == nil {
+			changes[dir] = nil
+			continue
+		}
+		for name := range names {
+			changes[dir] = append(changes[dir], name)
+		}
+	}
+	w.changes = map[string]map[string]bool{}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+	default:
+		w.notify(changes)
+	}
+}
+
+// WatchPaths returns local directory paths shown in the current workspace.
+func (f *Filer) WatchPaths() []string {
+	paths := []string{}
+	for _, d := range f.Workspace().Dirs {
+		if !d.IsRemote() {
+			paths = append(paths, d.Path)
+		}
+	}
+	return paths
+}
+
+// Refresh updates directories in the current workspace by changes.
+func (f *Filer) Refresh(changes Changes) {
+	for _, d := range f.Workspace().Dirs {
+		if names, ok := changes[filepath.Clean(d.Path)]; ok {
+			d.refresh(names)
+		}
+	}
+}
+
+// refresh updates the changed files keeping the cursor and marks. Reloads
+// entirely if names is nil or the directory is not listed as it is.
+func (d *Directory) refresh(names []string) {
+	current := ""
+	if !d.IsEmpty() {
+		current = d.File().Name()
+	}
+	cursor := d.Cursor()
+
+	switch {
+	case d.finder != nil:
+		return // keeps the filtered list until the finder exits
+	case d.loader != nil:
+		return // loads the latest files already
+	case names == nil || !isDefaultReader(d.reader):
+		wd, err := os.Getwd()
+		if err != nil || os.Chdir(d.Path) != nil {
+			return
+		}
+		d.read()
+		_ = os.Chdir(wd)
+	default:
+		d.update(names)
+	}
+
+	d.SetCursor(cursor)
+	for i, e := range d.List() {
+		if e.Name() == current {
+			d.SetCursor(i)
+			break
+		}
+	}
+	d.AdjustOffset()
+}
+
+// update replaces file stats of the names with the current states.
+func (d *Directory) update(names []string) {
+	changed := make(map[string]bool, len(names))
+	for _, name := range names {
+		changed[name] = true
+	}
+	marked := map[string]bool{}
+	list := d.List()[:0]
+	for _, e := range d.List() {
+		fs := e.(*FileStat)
+		if fs.Name() == ".." {
+			continue
+		} else if changed[fs.Name()] {
+			marked[fs.Name()] = fs.IsMarked()
+			continue
+		}
+		list = append(list, e)
+	}
+	for name := range changed {
+		if !d.showHiddens() && name[0] == '.' {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(d.Path, name)); err != nil {
+			continue // removed or renamed
+		}
+		if fs := NewFileStat(d.Path, name); fs != nil {
+			if marked[name] {
+				fs.Mark()
+			}
+			list = append(list, fs)
+		}
+	}
+	d.SetList(list)
+	if d.IsEmpty() {
+		d.AppendList(d.stat(".."))
+	}
+	d.sortList()
+}
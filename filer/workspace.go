@@ -17,6 +17,7 @@ const (
 	layoutOneline
 	layoutOneColumn
 	layoutFullscreen
+	layoutPreview
 )
 
 // Workspace is a box storing and layouting directories.
@@ -26,6 +27,8 @@ type Workspace struct {
 	Layout layoutType   `json:"layout"`
 	Title  string       `json:"title"`
 	Focus  int          `json:"focus"`
+	last   layoutType   // the layout before the preview
+	view   *preview
 }
 
 // NewWorkspace returns a new workspace of specified sizes.
@@ -36,6 +39,8 @@ func NewWorkspace(x, y, width, height int, title string) *Workspace {
 		layoutTile,
 		title,
 		0,
+		layoutTile,
+		nil,
 	}
 }
 
@@ -114,6 +119,9 @@ func (w *Workspace) SetFocus(x int) {
 }
 
 func (w *Workspace) attach() {
+	if w.Dir().IsRemote() {
+		return
+	}
 	err := os.Chdir(w.Dir().Path)
 	if err != nil {
 		message.Error(err)
@@ -127,6 +135,12 @@ func (w *Workspace) ReloadAll() {
 	for _, d := range w.Dirs {
 		d.reload()
 	}
+	if w.view != nil {
+		w.view.path = "" // reads again
+	}
+	if w.Dir().IsRemote() {
+		return
+	}
 	err := os.Chdir(w.Dir().Path)
 	if err != nil {
 		message.Error(err)
@@ -298,6 +312,34 @@ func (w *Workspace) LayoutFullscreen() {
 	}
 }
 
+// LayoutPreview allocates the focused directory and the preview of the
+// cursor file side by side.
+func (w *Workspace) LayoutPreview() {
+	if w.Layout != layoutPreview {
+		w.last = w.Layout // restored by TogglePreview
+	}
+	w.Layout = layoutPreview
+	if w.view == nil {
+		w.view = newPreview()
+	}
+	x, y := w.LeftTop()
+	width := w.Width() / 2
+	for _, d := range w.Dirs {
+		d.Resize(x, y, width, w.Height())
+	}
+	w.view.Resize(x+width, y, w.Width()-width, w.Height())
+}
+
+// TogglePreview toggles the preview layout and the layout before it.
+func (w *Workspace) TogglePreview() {
+	if w.Layout == layoutPreview {
+		w.Layout = w.last
+		w.allocate()
+	} else {
+		w.LayoutPreview()
+	}
+}
+
 func (w *Workspace) allocate() {
 	switch w.Layout {
 	case layoutTile:
@@ -312,6 +354,8 @@ func (w *Workspace) allocate() {
 		w.LayoutOnecolumn()
 	case layoutFullscreen:
 		w.LayoutFullscreen()
+	case layoutPreview:
+		w.LayoutPreview()
 	}
 }
 
@@ -331,6 +375,9 @@ func (w *Workspace) ResizeRelative(x, y, width, height int) {
 func (w *Workspace) Draw() {
 	if w.Layout == layoutFullscreen {
 		w.Dir().draw(true)
+	} else if w.Layout == layoutPreview {
+		w.Dir().draw(true)
+		w.view.draw(w.Dir())
 	} else {
 		w.draw()
 	}
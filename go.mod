@@ -3,17 +3,19 @@ module github.com/epainos/gofuli
 go 1.16
 
 require (
+	github.com/alecthomas/chroma v0.10.0
 	github.com/atotto/clipboard v0.1.4
 	github.com/conformal/gotk3 v0.0.0-20140908210829-7a6ce3ecbc88 // indirect
 	github.com/f1bonacc1/glippy v0.0.0-20230614190937-e7ca07f99f6f
+	github.com/fsnotify/fsnotify v1.5.4
 	github.com/gdamore/tcell/v2 v2.4.0
 	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.13
+	github.com/pelletier/go-toml/v2 v2.2.2
 	github.com/tjgq/clipboard v0.0.0-20140914215156-35a41f2605b7
 	github.com/tjgq/ticker v0.0.0-20140913211110-8b4870134629 // indirect
-	golang.org/x/sys v0.0.0-20211124211545-fe61309f8881 // indirect
+	github.com/yuin/gopher-lua v1.1.1
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
-	golang.org/x/text v0.3.7 // indirect
-
+	golang.org/x/text v0.3.7
 )
@@ -26,7 +26,18 @@ func (w *infoWindow) draw(fi os.FileInfo) {
 	used := float64(all-free) / float64(all) * 100
 	freeSI := util.FormatSize(int64(free))
 
-	stat := fi.Sys().(*syscall.Stat_t)
+	perm := fi.Mode().String()
+	size := fi.Size()
+	mtime := fi.ModTime().String()
+	name := fi.Name()
+
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok { // not on the local file system
+		info := fmt.Sprintf("%s %d %s %s", perm, size, mtime, name)
+		s := runewidth.Truncate(info, w.Width(), "~")
+		widget.SetCells(x, y, s, look.Default())
+		return
+	}
 	var username, group string
 	if u, err := user.LookupId(fmt.Sprintf("%d", stat.Uid)); err != nil {
 		username = "unknown"
@@ -39,11 +50,6 @@ func (w *infoWindow) draw(fi os.FileInfo) {
 		group = u.Name
 	}
 
-	perm := fi.Mode().String()
-	size := fi.Size()
-	mtime := fi.ModTime().String()
-	name := fi.Name()
-
 	info := fmt.Sprintf("%s free %.1f%% used %s %s %s %d %d %s %s",
 		freeSI, used, perm, username, group, stat.Nlink, size, mtime, name)
 	s := runewidth.Truncate(info, w.Width(), "~")
@@ -1,25 +1,71 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/epainos/gofuli/app"
 	"github.com/epainos/gofuli/cmdline"
+	"github.com/epainos/gofuli/control"
 	"github.com/epainos/gofuli/filer"
 	"github.com/epainos/gofuli/look"
 	"github.com/epainos/gofuli/menu"
 	"github.com/epainos/gofuli/message"
+	"github.com/epainos/gofuli/s3"
 	"github.com/epainos/gofuli/util"
 	"github.com/epainos/gofuli/widget"
 	"github.com/f1bonacc1/glippy"
 	"github.com/mattn/go-runewidth"
 )
 
+// confDir is the directory of the state and configuration files.
+var confDir = "~/.goful"
+
+// confPath returns the path of the file in the configuration directory.
+func confPath(name string) string { return filepath.Join(confDir, name) }
+
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gofuli [flags] [LEFT [RIGHT]]")
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&confDir, "dir", confDir, "the directory of the state and config files")
+	left := flag.String("left", "", "open the directory or the file in the left pane")
+	right := flag.String("right", "", "open the directory or the file in the right pane")
+	readOnly := flag.Bool("read-only", false, "disable file operations and the shell mode")
+	cdFile := flag.String("cd-file", os.Getenv("GOFUL_CD_FILE"), "write the focused directory to the file on quit, for the shell to change the directory")
+	choose := flag.String("choose-files", "", "choose files by enter writing their paths to the file, or - for stdout, and quit")
+	s3Endpoint := flag.String("s3-endpoint", "", "the endpoint URL of S3-compatible storage such as http://localhost:9000")
+	remote := flag.Bool("remote", false, "send the command to the running goful: chdir PATH, select PATH..., get-marked, get-state, exec ACTION")
+	flag.Parse()
+	app.SetControlSocket(confPath("gofuli.sock"))
+	if *remote {
+		os.Exit(remoteCommand(flag.Args()))
+	}
+	if args := flag.Args(); len(args) > 2 {
+		flag.Usage()
+		os.Exit(2)
+	} else if len(args) > 0 && *left == "" {
+		*left = args[0]
+		if len(args) > 1 && *right == "" {
+			*right = args[1]
+		}
+	}
+	app.SetReadOnly(*readOnly)
+	app.SetChooser(*choose)
+	app.SetCdFile(*cdFile)
+	if *s3Endpoint != "" {
+		s3.SetEndpoint(*s3Endpoint)
+	}
 
 	is_tmux := false
+	defer app.WriteChosen() // after the screen is finished
 	widget.Init()
 	defer widget.Fini()
 
@@ -29,25 +75,72 @@ func main() {
 		is_tmux = strings.Contains(os.Getenv("TERM"), "screen")
 	}
 	// Change a terminal title.
-	if is_tmux {
+	switch {
+	case *choose == "-": // stdout is for chosen paths
+	case is_tmux:
 		os.Stdout.WriteString("\033kgoful\033") // for tmux
-	} else {
+	default:
 		os.Stdout.WriteString("\033]0;goful\007") // for otherwise
 	}
 
-	const state = "~/.goful/state.json"
-	const history = "~/.goful/history/shell"
+	state := confPath("state.json")
+	history := confPath("history/shell")
+	views := confPath("views.json")
+	basket := confPath("basket.json") // "" is not saving the basket
+	frecency := confPath("frecency.json")
 
+	_ = filer.LoadViews(views)
+	_ = filer.LoadBasket(basket)
+	_ = filer.LoadFrecency(frecency)
 	goful := app.NewGoful(state)
 	config(goful, is_tmux)
+	goful.LoadScripts(confPath("scripts")) // before the config binding script commands
+	if err := goful.LoadConfig(confPath("config.toml")); err != nil {
+		message.Error(err)
+	}
 	_ = cmdline.LoadHistory(history)
-	goful.OpenMyAppList("~/.goful/myApp")           // myfOpenMyAppList 메서드 호출
-	goful.OpenMyBookmarkList("~/.goful/myBookmark") // myfOpenMyBookmarkList 메서드 호출
+	goful.LoadBookmarks(confPath("bookmarks.json")) // migrates old myBookmark and myApp files
+	goful.OpenPanes(*left, *right)
 
 	goful.Run()
 
-	_ = goful.SaveState(state)
+	if !app.IsChooser() { // not to change the saved panes by picking files
+		_ = goful.SaveState(state)
+	}
 	_ = cmdline.SaveHistory(history)
+	_ = filer.SaveFrecency()
+}
+
+// remoteCommand sends the command to the running goful and prints the reply.
+func remoteCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: gofuli --remote chdir PATH | select PATH... | get-marked | get-state | exec ACTION")
+		return 2
+	}
+	req := &control.Request{Command: args[0], Args: args[1:]}
+	if req.Command == "chdir" || req.Command == "select" {
+		for i, arg := range req.Args {
+			if path, err := filepath.Abs(util.ExpandPath(arg)); err == nil {
+				req.Args[i] = path
+			}
+		}
+	}
+	res, err := control.Send(app.ControlSocket(), req, 5*time.Second)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gofuli:", err)
+		return 1
+	}
+	switch req.Command {
+	case "get-marked":
+		for _, path := range res.State.Marked {
+			fmt.Println(path)
+		}
+	case "get-state":
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		e.Encode(res.State)
+	}
+	return 0
 }
 
 func config(g *app.Goful, is_tmux bool) {
@@ -65,9 +158,9 @@ func config(g *app.Goful, is_tmux bool) {
 	}
 	g.SetBorderStyle(widget.ULBorder) // AllBorder, ULBorder, NoBorder
 
-	message.SetInfoLog("~/.goful/log/info.log")   // "" is not logging
-	message.SetErrorLog("~/.goful/log/error.log") // "" is not logging
-	message.Sec(3)                                // display second for a message
+	message.SetInfoLog(confPath("log/info.log"))   // "" is not logging
+	message.SetErrorLog(confPath("log/error.log")) // "" is not logging
+	message.Sec(3)                                 // display second for a message
 
 	// Setup widget keymaps.
 	g.ConfigFiler(filerKeymap)
@@ -78,6 +171,10 @@ func config(g *app.Goful, is_tmux bool) {
 
 	filer.SetStatView(true, false, false) // size, permission and time
 	filer.SetTimeFormat("060102_15:04")   // ex: "Jan _2 15:04"
+	filer.SetCollateLocale("")            // locale for the locale sort such as "ko", empty is from LANG
+	filer.SetGrepGitignore(true)          // grep skips files ignored by .gitignore
+	filer.SetFindDepth(0)                 // depth to walk for the find query, 0 is unlimited
+	app.SetClipboardExport(true)          // yank also sets paths to the system clipboard
 
 	// Setup open command for C-m (when the enter key is pressed)
 	// The macro %f means expanded to a file name, for more see (spawn.go)
@@ -165,6 +262,11 @@ func config(g *app.Goful, is_tmux bool) {
 		"T", "sort time decending 시간 역순", func() { g.Dir().SortMtimeDec() },
 		"e", "sort ext            확장자별          ", func() { g.Dir().SortExt() },
 		"E", "sort ext decending  확장자역수", func() { g.Dir().SortExtDec() },
+		"a", "sort natural        자연순         ", func() { g.Dir().SortNatural() },
+		"A", "sort natural decending 자연 역순", func() { g.Dir().SortNaturalDec() },
+		"l", "sort locale         가나다순        ", func() { g.Dir().SortLocale() },
+		"L", "sort locale decending 가나다 역순", func() { g.Dir().SortLocaleDec() },
+		"m", "sort multi keys     다중 정렬      ", func() { g.SortKeys() },
 		".", "toggle priority     폴더를 따로 정렬   ", func() { filer.TogglePriority(); g.Workspace().ReloadAll() },
 	)
 	g.AddKeymap("s", func() { g.Menu("sort") })
@@ -175,6 +277,12 @@ func config(g *app.Goful, is_tmux bool) {
 		"L", "look menu                 보기메뉴   ", func() { g.Menu("look") },
 		"t", "tab menu                  탭메뉴     ", func() { g.Menu("tab") },
 		".", "toggle show hidden files  숨김파일 켬/끔", func() { filer.ToggleShowHiddens(); g.Workspace().ReloadAll() },
+		"T", "toggle tree view          트리보기 켬/끔", func() { g.Dir().ToggleTree() },
+		"f", "toggle flatten view       펼쳐보기 켬/끔", func() { g.Flatten() },
+		"r", "remember view here        현재보기 기억", func() { g.Dir().RememberView() },
+		"R", "forget view here          현재보기 잊기", func() { g.Dir().ForgetView(); g.Workspace().ReloadAll() },
+		"p", "(C-space) toggle preview  미리보기 켬/끔", func() { g.Workspace().TogglePreview() },
+		"q", "quick look by external    외부 빠른보기", ifElse(runtime.GOOS == "windows", func() { g.Spawn(`~/AppData/Local/Programs/QuickLook/quickLook.exe '` + g.File().Path() + `'`) }, ifElse(runtime.GOOS == "darwin", func() { g.Spawn("qlmanage -p " + g.File().Name()) }, func() { g.Spawn(" sushi " + g.File().Path()) })),
 	)
 	g.AddKeymap("v", func() { g.Menu("view") })
 
@@ -185,12 +293,14 @@ func config(g *app.Goful, is_tmux bool) {
 		"r", "one-row      행 정렬", func() { g.Workspace().LayoutOnerow() },
 		"c", "one-column   열 정렬", func() { g.Workspace().LayoutOnecolumn() },
 		"f", "fullscreen   전체화면", func() { g.Workspace().LayoutFullscreen() },
+		"p", "preview      미리보기", func() { g.Workspace().LayoutPreview() },
 	)
 
 	menu.Add("stat",
 		"s", "Size           용량 켬/끔  ", func() { filer.ToggleSizeView() },
 		"p", "Permision      권한 켬/끔  ", func() { filer.TogglePermView() },
 		"t", "Time           날짜 켬/끔  ", func() { filer.ToggleTimeView() },
+		"d", "Dir size       폴더용량 켬/끔", func() { filer.ToggleDirSizeView(); g.Workspace().ReloadAll() },
 		"e", "Essential      용량만 보임     ", func() { filer.SetStatView(true, false, false) },
 		"a", "size+per+time  용량+권한+날짜     ", func() { filer.SetStatView(true, true, true) },
 		"n", "noting         없음      ", func() { filer.SetStatView(false, false, false) },
@@ -219,11 +329,56 @@ func config(g *app.Goful, is_tmux bool) {
 		"D", "(D) chdir           경로 이동       ", func() { g.Chdir() },
 		"g", "(g) glob            찾기 ", func() { g.Glob() },
 		"G", "(G) globdir         찾기(하부폴더)", func() { g.Globdir() },
+		"s", "(M-g) grep          내용 검색", func() { g.Grep() },
+		"q", "(M-f) find query    속성 검색", func() { g.Find() },
 		"b", "(B) go pre dir      폴더 뒤로 가기", func() { g.Dir().GoPreviousFolder() },
 		"f", "(F) go forward dir  폴더 앞으로 가기", func() { g.Dir().GoFowardFolder() },
+		"v", "(M-h) visited dirs  방문 폴더    ", func() { g.VisitedDir(false) },
+		"V", "recent dirs         최근 폴더(전체)", func() { g.VisitedDir(true) },
+		"j", "(M-j) jump          자주 가는 폴더로 ", func() { g.Jump() },
+		"J", "import jump dirs    점프 가져오기  ", func() { g.JumpImport() },
+		"e", "(M-x) script        스크립트 실행   ", func() { g.Script() },
 	)
 	g.AddKeymap("x", func() { g.Menu("command") })
 
+	menu.Add("mark",
+		"g", "mark by glob          패턴으로 선택    ", func() { g.Mark("glob", "*", true) },
+		"G", "unmark by glob        패턴으로 선택 해제 ", func() { g.Mark("glob", "*", false) },
+		"r", "mark by regexp        정규식으로 선택   ", func() { g.Mark("regexp", "", true) },
+		"R", "unmark by regexp      정규식으로 선택 해제", func() { g.Mark("regexp", "", false) },
+		"n", "mark newer than       최근 파일 선택    ", func() { g.Mark("query", "mtime<1d", true) },
+		"s", "mark larger than      큰 파일 선택     ", func() { g.Mark("query", "size>10M", true) },
+		"e", "mark by extension     확장자로 선택    ", func() { g.Mark("query", "ext:", true) },
+		"E", "mark same extension   같은 확장자 선택  ", func() { g.Dir().MarkSameExt() },
+		"q", "mark by query         조건으로 선택    ", func() { g.Mark("query", "", true) },
+		"v", "(V) visual range      범위 선택       ", func() { g.Dir().ToggleVisual() },
+		"i", "(`) invert marks      선택 반전       ", func() { g.Dir().InvertMark() },
+		"u", "restore marks         선택 복구       ", func() { g.Dir().RestoreMarks() },
+	)
+	g.AddKeymap("M-m", func() { g.Menu("mark") })
+
+	menu.Add("basket",
+		"a", "add %m to basket        바구니에 담기  ", func() { g.BasketAdd() },
+		"v", "view basket             바구니 보기   ", func() { g.Dir().ShowBasket() },
+		"d", "remove %m from basket   바구니에서 빼기 ", func() { g.BasketRemove() },
+		"C", "clear basket            바구니 비우기  ", func() { g.BasketClear() },
+		"c", "copy basket here        여기로 복사    ", func() { g.BasketCopy() },
+		"m", "move basket here        여기로 이동    ", func() { g.BasketMove() },
+		"z", "archive basket here     여기에 압축    ", func() { g.BasketArchive() },
+		"D", "delete basket files     바구니 파일 삭제 ", func() { g.BasketDelete() },
+	)
+	g.AddKeymap("M-a", func() { g.Menu("basket") })
+
+	menu.Add("git",
+		"a", "stage %m            스테이지 추가   ", func() { g.GitStage() },
+		"u", "unstage %m          스테이지 취소   ", func() { g.GitUnstage() },
+		"r", "restore %m          변경 되돌리기   ", func() { g.GitRestore() },
+		"d", "diff %m             변경 보기      ", func() { g.GitDiff(false) },
+		"D", "diff staged %m      스테이지 변경 보기", func() { g.GitDiff(true) },
+		"g", "toggle git status   깃 상태 켬/끔   ", func() { filer.ToggleGitView(); g.Workspace().ReloadAll() },
+	)
+	g.AddKeymap("i", func() { g.Menu("git") })
+
 	if runtime.GOOS == "windows" {
 		menu.Add("external-command",
 			"c", "(f5) copy %m to %D2   복사", ifElse(runtime.GOOS == "windows", func() { g.Shell(`fcp /cmd=force_copy %M /to='%~D2/'`, -7) }, func() { g.Shell(`cp -r -v %M %D2`, -7) }), //func() { g.Shell(`fcp /cmd=diff '%~F' /to='%~D2'`) },
@@ -323,6 +478,7 @@ func config(g *app.Goful, is_tmux bool) {
 	menu.Add("myBookmark",
 		"+", "add myBookmark   바로가기 추가 ", func() { g.AddMyBookmark() },
 		"-", "del myBookmark   바로가기 제거 ( DELETE key also can delete bookmark on cursor  )", func() { g.DelMyBookmark() },
+		"=", "edit myBookmark  바로가기 수정 ", func() { g.EditMyBookmark() },
 	)
 	g.AddKeymap("B", func() { g.Menu("myBookmark") })
 
@@ -338,6 +494,7 @@ func config(g *app.Goful, is_tmux bool) {
 	menu.Add("myApp",
 		"+", "add MyApp   사용자앱 추가 ", func() { g.AddMyApp() },
 		"-", "del MyApp   사용자앱 제거 ( DELETE key also can delete app on cursor  )", func() { g.DelMyApp() },
+		"=", "edit MyApp  사용자앱 수정 ", func() { g.EditMyApp() },
 	)
 	g.AddKeymap("E", func() { g.Menu("myApp") })
 
@@ -358,6 +515,7 @@ func config(g *app.Goful, is_tmux bool) {
 	associate = widget.Keymap{
 		".dir":  func() { g.Dir().EnterDir(); g.Workspace().ReloadAll() },
 		".exec": func() { g.Shell(" ./" + g.File().Name()) },
+		".grep": func() { g.Spawn("vim +%l %f") }, // a file listed by grep at the matched line
 
 		".zip": func() { g.Shell(`7z x '%~F' -o'%~D/%~x'`) },
 		".tar": func() { g.Shell(`7z x '%~F' -o'%~D/%~x'`) }, //func() { g.Shell(`tar xvf %f -C %D`) },
@@ -405,101 +563,6 @@ func ifElse(condition bool, trueVal func(), falseVal func()) func() {
 	return falseVal
 }
 
-func arrangeFilePathForWindows(str string) string {
-	if strings.Contains(str, `"`) || strings.Contains(str, `'`) { //case for copied file path.
-		//"C:\Windows"
-		//"C:\Users"
-		//      -> 'C:/Windows' 'C:/Users'
-		return strings.Replace(strings.Replace(strings.Replace(str, "\r\n", ` `, -1), `\`, `/`, -1), `"`, `'`, -1)
-	} else { //case for copied file
-		// c:\Users c:\Windows -> 'c:/Users' 'c:/Windows'
-		if str == "" {
-			return `==PLEASE COPY FILE PATH BY 'shift + rightClick + a'===`
-		} else {
-			str = strings.Replace(strings.Replace(str, `\`, `/`, -1), "\r\n", ` `, -1)
-			println(str)
-			var indices []int
-			indices = append(indices, 0)
-			for i := 1; i < len(str); i++ {
-				if str[i] == ':' { //find ':' and add index //c:/Users 라고 하면 :을 기준으로 검색후, 앞자리 두개를 잘라오는 형태임
-					indices = append(indices, i-1) //
-				}
-			}
-			indices = append(indices, len(str))
-			returnString := ``
-			for i := 1; i < len(indices)-1; i++ { //add ' ' between strings
-				// println(str[indices[i]:indices[i+1]])
-				returnString += `'` + strings.TrimSpace(str[indices[i]:indices[i+1]]) + `' `
-			}
-			return strings.ReplaceAll(returnString, `''`, `'`)
-		}
-	}
-}
-
-func arrangeFilePathForMac(str string) string {
-	//case for copied file path.
-	// 	/Users
-	// /System
-	// /Applications -> '/Users' '/System' '/Applications'
-	if strings.Contains(str, "\n") {
-		return strings.ReplaceAll(`'`+strings.Replace(str, "\n", `' '`, -1)+`'`, `''`, `'`)
-	} else {
-		//case for copied file
-		// /Users /System /Applications -> '/Users' '/System' '/Applications'
-		if str == "" {
-			return `==PLEASE COPY FILE PATH BY 'cmd + opt + c' in finder===`
-		} else {
-			var indices []int
-
-			indices = append(indices, 0)
-			for i := 1; i < len(str); i++ {
-				if str[i-1] == ' ' && str[i] == '/' { //find ':' and add index
-					indices = append(indices, i-1) //
-				}
-			}
-			indices = append(indices, len(str))
-
-			returnString := ""
-			for i := 0; i < len(indices)-1; i++ { //add ' ' between strings
-				returnString += `'` + strings.TrimSpace(str[indices[i]:indices[i+1]]) + `' `
-			}
-			return strings.ReplaceAll(returnString, `''`, `'`)
-		}
-	}
-}
-
-func arrangeFilePathForLin(str string) string {
-	//case for copied file path.
-	// 	/Users
-	// /System
-	// /Applications -> '/Users' '/System' '/Applications'
-	if strings.Contains(str, "\n") {
-		return strings.ReplaceAll(`'`+strings.Replace(str, "\n", `' '`, -1)+`'`, `''`, `'`)
-	} else {
-		//case for copied file
-		// /Users /System /Applications -> '/Users' '/System' '/Applications'
-		if str == "" {
-			return `===your path is EMPTY ===`
-		} else {
-			var indices []int
-
-			indices = append(indices, 0)
-			for i := 1; i < len(str); i++ {
-				if str[i-1] == ' ' && str[i] == '/' { //find ':' and add index
-					indices = append(indices, i-1) //
-				}
-			}
-			indices = append(indices, len(str))
-
-			returnString := ""
-			for i := 0; i < len(indices)-1; i++ { //add ' ' between strings
-				returnString += `'` + strings.TrimSpace(str[indices[i]:indices[i+1]]) + `' `
-			}
-			return strings.ReplaceAll(returnString, `''`, `'`)
-		}
-	}
-}
-
 // Widget keymap functions.
 
 func filerKeymap(g *app.Goful) widget.Keymap {
@@ -514,6 +577,7 @@ func filerKeymap(g *app.Goful) widget.Keymap {
 
 		"a": func() { g.Shell(`7z a '%~D2/%~d.zip' %M`, -7) }, //zip to neighbor folder
 		"A": func() { g.Shell(`7z a '%~d.zip' %M`, -7) },      //zip to current folder
+		//M-a: basket menu
 
 		// "b": func() { g.Menu("bookmark") }
 		// "B": func() { g.Menu("myBookmark") }
@@ -545,22 +609,28 @@ func filerKeymap(g *app.Goful) widget.Keymap {
 			message.Info("file Name copied(파일명 복사함): " + myClip)
 		},
 
-		"g": func() { g.Glob() },    //search file in current folder
-		"G": func() { g.Globdir() }, //search file in current folder and subfolders
+		"g":   func() { g.Glob() },    //search file in current folder
+		"G":   func() { g.Globdir() }, //search file in current folder and subfolders
+		"M-g": func() { g.Grep() },    //search file contents in current folder and subfolders
+		"M-f": func() { g.Find() },    //search files by attributes in current folder and subfolders
 
-		"h": func() { g.Dir().Chdir("..") },                    //go to parent folder //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
-		"H": func() { g.Workspace().Dir().GoPreviousFolder() }, //go to previous folder
+		"h":   func() { g.Dir().Chdir("..") },                    //go to parent folder //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
+		"H":   func() { g.Workspace().Dir().GoPreviousFolder() }, //go to previous folder
+		"M-h": func() { g.VisitedDir(false) },                    //pick a visited folder of this window
 		// "i": func() { g.Dir().MoveCursor(-5) }, //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
 		// "I": func() { g.Dir().MoveTop() },      //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
+		//i: git menu
 
-		"j": func() { g.Dir().MoveCursor(1) }, //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
-		"J": func() { g.Dir().MoveCursor(5) },
-		"k": func() { g.Dir().MoveCursor(-1) }, //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
-		"K": func() { g.Dir().MoveCursor(-5) },
+		"j":   func() { g.Dir().MoveCursor(1) }, //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
+		"J":   func() { g.Dir().MoveCursor(5) },
+		"M-j": func() { g.Jump() },               //jump to a frecent folder matching keywords
+		"k":   func() { g.Dir().MoveCursor(-1) }, //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
+		"K":   func() { g.Dir().MoveCursor(-5) },
 		// "l":  open file with default application
 		"L": func() { g.Workspace().Dir().GoFowardFolder() },
 		"m": func() { g.Move() },                                                                                               //move file
 		"M": ifElse(runtime.GOOS == "windows", func() { message.Info(`Windows doesn't need to chmod`) }, func() { g.Chmod() }), //change file permission
+		//M-m: mark menu
 		//C-M means enter. open file with default applicationmm
 
 		"n": func() { g.Touch() }, //new file
@@ -568,38 +638,12 @@ func filerKeymap(g *app.Goful) widget.Keymap {
 		//"o":  open file with default application
 		"O": ifElse(runtime.GOOS == "windows", func() { g.Spawn(`explorer . %&`) }, ifElse(runtime.GOOS == "darwin", func() { g.Spawn(`open %D %&`) }, func() { g.Spawn(`xdg-open %D %&`) })), //open folder with file manager
 
-		"p": //paste file 복사 파일 붙여넣기
-		ifElse(runtime.GOOS == "windows", func() {
-			value, _ := glippy.Get()
-			value = arrangeFilePathForWindows(value)
-			g.Shell(`fcp /cmd=force_copy `+value+` /to='%~D/'`, -7)
-		}, ifElse(runtime.GOOS == "darwin", func() {
-			value, _ := glippy.Get()
-			value = arrangeFilePathForMac(value)
-			g.Shell(`cp -r -v `+value+` %D`, -7)
-		}, func() {
-			value, _ := glippy.Get()
-			value = arrangeFilePathForLin(value)
-			g.Shell(`cp -r -v `+value+` %D`, -7)
-		})),
-
-		"P": //move file 복사파일 이동함
-		ifElse(runtime.GOOS == "windows", func() {
-			value, _ := glippy.Get()
-			value = arrangeFilePathForWindows(value)
-			g.Shell(`fcp /cmd=Move `+value+` /to='%~D/'`, -7)
-		}, ifElse(runtime.GOOS == "darwin", func() {
-			value, _ := glippy.Get()
-			value = arrangeFilePathForMac(value)
-			g.Shell(`mv -f -v `+value+` %D`, -7)
-		}, func() {
-			value, _ := glippy.Get()
-			value = arrangeFilePathForLin(value)
-			g.Shell(`mv -f -v `+value+` %D`, -7)
-		})),
+		"p": func() { g.Paste(false) }, //paste yanked files here, or move if cut 붙여넣기
+		"P": func() { g.Paste(true) },  //move yanked files here 이동
 
-		"q": func() { g.Quit() },
-		"Q": func() { g.Workspace().SwapNextDir() },
+		"q":   func() { g.Quit() },
+		"M-q": func() { g.QuitNoCd() }, //quit without changing the directory of the shell
+		"Q":   func() { g.Workspace().SwapNextDir() },
 
 		"r":   func() { g.Rename() },
 		"R":   func() { g.BulkRename() },
@@ -617,7 +661,7 @@ func filerKeymap(g *app.Goful) widget.Keymap {
 		// "U": func() { g.Dir().MoveBottom() },  //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
 
 		//v: view menu
-		//"V":
+		"V": func() { g.Dir().ToggleVisual() }, //mark files between here and the cursor
 
 		"w":   func() { g.Workspace().ReloadAll(); g.Workspace().ChdirNeighbor2This() }, //change next window to this folder
 		"W":   func() { g.Workspace().ReloadAll(); g.Workspace().ChdirNeighbor() },      // change this window to next folder
@@ -625,13 +669,11 @@ func filerKeymap(g *app.Goful) widget.Keymap {
 		"M-w": func() { g.Workspace().ReloadAll(); g.Workspace().CloseDir() },           //close window
 
 		//"x": command menu
+		"C-x": func() { g.Cut() },    //cut files to move by paste 잘라내기
+		"M-x": func() { g.Script() }, //run a command of lua scripts 스크립트 실행
 		//"X": external command menu
 
-		"y": func() { //file copy 파일 복사
-			myClip := strings.Join(g.Dir().MarkfileQuotedPaths(), " ")
-			glippy.Set(myClip)
-			message.Info("Yanked file(파일 복사함)): " + myClip)
-		},
+		"y": func() { g.Yank() }, //yank files to paste 복사
 		"Y": func() { //Path copy 경로 복사
 			myClip := util.RemoveExt(g.File().Path())
 			glippy.Set(myClip)
@@ -643,7 +685,7 @@ func filerKeymap(g *app.Goful) widget.Keymap {
 
 		// function keys do External command
 		"f2": ifElse(runtime.GOOS == "windows", func() { g.Shell("move %F './" + g.File().Name() + `'`) }, func() { g.Shell("mv -vi %f '" + g.File().Name() + `'`) }),
-		"f3": ifElse(runtime.GOOS == "windows", func() { g.Spawn(`~/AppData/Local/Programs/QuickLook/quickLook.exe '` + g.File().Path() + `'`) }, ifElse(runtime.GOOS == "darwin", func() { g.Spawn("qlmanage -p " + g.File().Name()) }, func() { g.Spawn(" sushi " + g.File().Path()) })),
+		"f3": func() { g.Workspace().TogglePreview() }, //preview the cursor file in the next half
 
 		"f5": ifElse(runtime.GOOS == "windows", func() { g.Shell(`fcp /cmd=force_copy %M /to='%~D2/'`, -7) }, func() { g.Shell(`cp -r -v %M %D2`, -7) }),
 		"f6": ifElse(runtime.GOOS == "windows", func() { g.Shell(`fcp /cmd=move %M /to='%~D2/'`, -7) }, func() { g.Shell(`mv -f -v %M %D2`, -7) }),
@@ -656,6 +698,7 @@ func filerKeymap(g *app.Goful) widget.Keymap {
 		"delete": func() { g.Remove() }, //delete
 
 		"'": func() { g.Dir().Reset(); g.Workspace().ReloadAll() }, //reset
+		"+": func() { g.Dir().ToggleExpand() },                     //expand or collapse folder in tree view
 
 		"~":  func() { g.Dir().Chdir("~") },
 		"\\": func() { g.Dir().Chdir("/") },
@@ -674,7 +717,7 @@ func filerKeymap(g *app.Goful) widget.Keymap {
 		"pgup": func() { g.Dir().PageUp() },     //hjkl ←↓↑→,    ui ↟↡,    ^,U = Home,    $, I = End
 
 		" ":       func() { g.Dir().ToggleMark() }, //space key
-		"C-space": ifElse(runtime.GOOS == "windows", func() { g.Spawn(`~/AppData/Local/Programs/QuickLook/quickLook.exe '` + g.File().Path() + `'`) }, ifElse(runtime.GOOS == "darwin", func() { g.Spawn("qlmanage -p " + g.File().Name()) }, func() { g.Spawn(" sushi " + g.File().Path()) })),
+		"C-space": func() { g.Workspace().TogglePreview() },
 
 		"`": func() { g.Dir().InvertMark() },
 
@@ -689,6 +732,7 @@ func finderKeymap(w *filer.Finder) widget.Keymap {
 		"backspace": func() { w.DeleteBackwardChar() },
 		"C-g":       func() { w.Exit() }, //
 		"C-[":       func() { w.Exit() },
+		"C-s":       func() { w.ToggleFuzzy() }, // regexp or fuzzy
 	}
 }
 
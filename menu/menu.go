@@ -2,15 +2,11 @@
 package menu
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/epainos/gofuli/message"
-	"github.com/epainos/gofuli/util"
 	"github.com/epainos/gofuli/widget"
-	"github.com/f1bonacc1/glippy"
 )
 
 var menusMap = map[string][]*menuItem{}
@@ -48,25 +44,29 @@ func Remove(name string, accel string) {
 	}
 	menusMap[name] = items
 }
+
+// RemoveMenuInWindow removes the menu item on the cursor by the remover.
 func (w *Menu) RemoveMenuInWindow() {
 	title := w.Title()
-	accel := menusMap[w.Title()][w.Cursor()].accel
-	nameToDel := menusMap[w.Title()][w.Cursor()].label
-	glippy.Set("menu:" + title + ":" + accel + ":" + nameToDel)
-
-	if !(title == "myApp" || title == "myBookmark") {
+	item := menusMap[title][w.Cursor()]
+	if remover == nil {
 		message.Errorf("Default menu cannot be removed... 기본 메뉴는 삭제할 수 없어요.")
 		return
-	} else if accel == "+" || accel == "-" {
-		message.Errorf("'add', 'del' cannot be removed... 추가,삭제는 삭제할 수 없어요.")
-	} else {
-		// glippy.Set("menu:" + w.Title() + ":" + accel)
-		DelMyAppFromListFile("~/.goful/"+title, accel)
-		message.Info("삭제 완료: " + nameToDel)
-
-		Remove(title, accel)
-		w.Exit()
 	}
+	if err := remover(title, item.accel); err != nil {
+		message.Error(err)
+		return
+	}
+	message.Info("삭제 완료: " + item.label)
+	w.Exit()
+}
+
+var remover func(name, accel string) error
+
+// ConfigRemover sets the function to remove a menu item by the delete key,
+// which returns an error if the item can not be removed.
+func ConfigRemover(remove func(name, accel string) error) {
+	remover = remove
 }
 
 var keymap func(*Menu) widget.Keymap
@@ -85,7 +85,8 @@ type menuItem struct {
 // Menu is a list box to execute for a acceleration key.
 type Menu struct {
 	*widget.ListBox
-	filer widget.Widget
+	filer   widget.Widget
+	pending string // keys typed for an acceleration key of multiple keys
 }
 
 // New creates a new menu based on filer widget sizes.
@@ -128,18 +129,29 @@ func (w *Menu) Exec() {
 }
 
 // Input to the list box or execute a menu item with the acceleration key.
+// Keys are accumulated while they are the prefix of acceleration keys.
 func (w *Menu) Input(key string) {
 	keymap := keymap(w)
 	if callback, ok := keymap[key]; ok {
+		w.pending = ""
 		callback()
-	} else {
-		for _, item := range menusMap[w.Title()] {
-			if item.accel == key {
-				w.Exit()
-				item.callback()
-			}
+		return
+	}
+	keys := w.pending + key
+	w.pending = ""
+	for _, item := range menusMap[w.Title()] {
+		if item.accel == keys {
+			w.Exit()
+			item.callback()
+			return
+		}
+		if strings.HasPrefix(item.accel, keys) {
+			w.pending = keys
 		}
 	}
+	if w.pending != "" {
+		message.Infof("%s-", keys)
+	}
 }
 
 // Exit the menu mode.
@@ -150,58 +162,3 @@ func (w *Menu) Next() widget.Widget { return widget.Nil() }
 
 // Disconnect implements widget.Widget.
 func (w *Menu) Disconnect() {}
-
-// DelMyAppFromListFile is a function to delete a shortcut from the myApp file.
-func DelMyAppFromListFile(path string, shortcutToDel string) {
-	// if path == "" {
-	// 	path = "~/.goful/myApp"
-	// }
-	file, err := os.OpenFile(util.ExpandPath(path), os.O_RDWR, 0644)
-	if err != nil {
-		fmt.Println("파일 열기 실패:", err)
-		return
-	}
-	defer file.Close()
-
-	fileInfo, err := os.Stat(util.ExpandPath(path))
-	if err != nil {
-		fmt.Println("파일 정보 가져오기 실패:", err)
-		return
-	}
-
-	if fileInfo.Size() == 0 {
-		fmt.Println("파일이 비어있습니다.")
-		return
-	}
-
-	var temp []byte
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, shortcutToDel) {
-			temp = append(temp, line...)
-			temp = append(temp, '\n')
-		}
-	}
-
-	if scanner.Err() != nil {
-		fmt.Println("파일 읽기 오류:", scanner.Err())
-		return
-	}
-
-	// 파일 크기 조절
-	if err := file.Truncate(0); err != nil {
-		fmt.Println("파일 크기 조절 실패:", err)
-		return
-	}
-
-	// 파일 처음부터 다시 쓰기
-	_, err = file.WriteAt(temp, 0)
-	if err != nil {
-		fmt.Println("파일 쓰기 실패:", err)
-		return
-	}
-
-	fmt.Println("삭제 완료")
-
-}
@@ -0,0 +1,112 @@
+package s3
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/epainos/gofuli/util"
+)
+
+// Credentials is an access key pair and an optional session token.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// config is the client settings read from the environment and profile files.
+type config struct {
+	creds    Credentials
+	region   string
+	endpoint string
+}
+
+// loadConfig reads settings in the same order as the AWS command line tools:
+// environment variables first and then the profile in ~/.aws/credentials and
+// ~/.aws/config.
+func loadConfig() config {
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+	credsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credsFile == "" {
+		credsFile = "~/.aws/credentials"
+	}
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = "~/.aws/config"
+	}
+	credsSection := readINI(credsFile)[profile]
+	configSection := readINI(configFile)["profile "+profile]
+	if profile == "default" || configSection == nil {
+		configSection = readINI(configFile)[profile]
+	}
+
+	lookup := func(envs []string, section map[string]string, key string) string {
+		for _, env := range envs {
+			if v := os.Getenv(env); v != "" {
+				return v
+			}
+		}
+		return section[key]
+	}
+
+	c := config{
+		creds: Credentials{
+			AccessKeyID:     lookup([]string{"AWS_ACCESS_KEY_ID"}, credsSection, "aws_access_key_id"),
+			SecretAccessKey: lookup([]string{"AWS_SECRET_ACCESS_KEY"}, credsSection, "aws_secret_access_key"),
+			SessionToken:    lookup([]string{"AWS_SESSION_TOKEN"}, credsSection, "aws_session_token"),
+		},
+		region:   lookup([]string{"AWS_REGION", "AWS_DEFAULT_REGION"}, configSection, "region"),
+		endpoint: lookup([]string{"AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"}, configSection, "endpoint_url"),
+	}
+	if c.creds.AccessKeyID == "" {
+		c.creds.AccessKeyID = configSection["aws_access_key_id"]
+		c.creds.SecretAccessKey = configSection["aws_secret_access_key"]
+		c.creds.SessionToken = configSection["aws_session_token"]
+	}
+	if c.region == "" {
+		c.region = "us-east-1"
+	}
+	return c
+}
+
+func readINI(path string) map[string]map[string]string {
+	file, err := os.Open(util.ExpandPath(path))
+	if err != nil {
+		return map[string]map[string]string{}
+	}
+	defer file.Close()
+	return parseINI(file)
+}
+
+// parseINI parses sections of key = value lines.
+// Comments begin with # or ; and keys are lower cased.
+func parseINI(r io.Reader) map[string]map[string]string {
+	sections := map[string]map[string]string{}
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		i := strings.IndexByte(line, '=')
+		if i < 0 {
+			continue
+		}
+		if sections[section] == nil {
+			sections[section] = map[string]string{}
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		sections[section][key] = strings.TrimSpace(line[i+1:])
+	}
+	return sections
+}
@@ -0,0 +1,425 @@
+// Package s3 is a small client for S3-compatible object storage.
+package s3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheme is the prefix of object storage paths like s3://bucket/prefix.
+const Scheme = "s3://"
+
+// IsPath reports whether the path is an object storage path.
+func IsPath(name string) bool {
+	return strings.HasPrefix(name, Scheme)
+}
+
+// Split splits the path into the bucket and the object key.
+func Split(name string) (bucket, key string) {
+	rest := strings.TrimPrefix(name, Scheme)
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return rest[:i], rest[i+1:]
+	}
+	return rest, ""
+}
+
+// Clean returns the shortest path equivalent to the path.
+// Parents of a bucket resolve to s3:// as the bucket list.
+func Clean(name string) string {
+	rest := path.Clean("/" + strings.TrimPrefix(name, Scheme))
+	return Scheme + strings.TrimPrefix(rest, "/")
+}
+
+// ObjectInfo describes an object or a common prefix as a directory.
+type ObjectInfo struct {
+	Key   string
+	name  string
+	size  int64
+	mtime time.Time
+	dir   bool
+}
+
+// Name returns the base name of the key.
+func (o *ObjectInfo) Name() string { return o.name }
+
+// Size returns the object size.
+func (o *ObjectInfo) Size() int64 { return o.size }
+
+// Mode returns the directory mode for prefixes.
+func (o *ObjectInfo) Mode() os.FileMode {
+	if o.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+// ModTime returns the last modified time.
+func (o *ObjectInfo) ModTime() time.Time { return o.mtime }
+
+// IsDir reports whether the common prefix or the bucket.
+func (o *ObjectInfo) IsDir() bool { return o.dir }
+
+// Sys returns nil.
+func (o *ObjectInfo) Sys() interface{} { return nil }
+
+// Error is an error response from the server.
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3: %s", http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("s3: %s: %s", e.Code, e.Message)
+}
+
+// IsNotExist reports whether the error is caused by a missing object or bucket.
+func IsNotExist(err error) bool {
+	if e, ok := err.(*Error); ok {
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// Client sends requests to the endpoint.
+type Client struct {
+	endpoint  *url.URL
+	region    string
+	creds     Credentials
+	pathStyle bool
+	http      *http.Client
+}
+
+var (
+	endpoint      string
+	defaultOnce   sync.Once
+	defaultClient *Client
+	defaultErr    error
+)
+
+// SetEndpoint sets the endpoint URL of a S3-compatible server such as
+// http://localhost:9000. It must be called before the first use.
+func SetEndpoint(url string) {
+	endpoint = url
+}
+
+// Default returns the client configured by the environment and profile files.
+func Default() (*Client, error) {
+	defaultOnce.Do(func() {
+		c := loadConfig()
+		if endpoint != "" {
+			c.endpoint = endpoint
+		}
+		defaultClient, defaultErr = New(c.endpoint, c.region, c.creds)
+	})
+	return defaultClient, defaultErr
+}
+
+// New creates a new client. The path style addressing is used if the endpoint
+// is specified, otherwise the virtual hosted style of AWS.
+func New(endpoint, region string, creds Credentials) (*Client, error) {
+	pathStyle := endpoint != ""
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("s3: invalid endpoint %s", endpoint)
+	}
+	return &Client{
+		endpoint:  u,
+		region:    region,
+		creds:     creds,
+		pathStyle: pathStyle,
+		http:      &http.Client{},
+	}, nil
+}
+
+func (c *Client) newRequest(method, bucket, key string, query map[string]string, body []byte) (*http.Request, error) {
+	u := *c.endpoint
+	p := "/"
+	if bucket != "" {
+		if c.pathStyle {
+			p += bucket + "/" + key
+		} else {
+			u.Host = bucket + "." + u.Host
+			p += key
+		}
+	}
+	u.Path = p
+	u.RawPath = escape(p, true)
+	u.RawQuery = canonicalQuery(query)
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	return req, nil
+}
+
+// do sends the request signed and returns an error for non 2xx responses.
+func (c *Client) do(req *http.Request, body []byte) (*http.Response, error) {
+	payloadHash := emptyHash
+	if len(body) > 0 {
+		payloadHash = hashHex(body)
+	}
+	sign(req, c.creds, c.region, payloadHash, time.Now())
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		e := &Error{StatusCode: resp.StatusCode}
+		data, _ := ioutil.ReadAll(resp.Body)
+		_ = xml.Unmarshal(data, e)
+		return nil, e
+	}
+	return resp, nil
+}
+
+func (c *Client) request(method, bucket, key string, query map[string]string, header http.Header, body []byte) (*http.Response, error) {
+	req, err := c.newRequest(method, bucket, key, query, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return c.do(req, body)
+}
+
+func decode(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	return xml.NewDecoder(resp.Body).Decode(v)
+}
+
+// ListBuckets lists all buckets of the account as directories.
+func (c *Client) ListBuckets() ([]*ObjectInfo, error) {
+	resp, err := c.request("GET", "", "", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Buckets []struct {
+			Name         string    `xml:"Name"`
+			CreationDate time.Time `xml:"CreationDate"`
+		} `xml:"Buckets>Bucket"`
+	}
+	if err := decode(resp, &result); err != nil {
+		return nil, err
+	}
+	buckets := make([]*ObjectInfo, len(result.Buckets))
+	for i, b := range result.Buckets {
+		buckets[i] = &ObjectInfo{Key: "", name: b.Name, mtime: b.CreationDate, dir: true}
+	}
+	return buckets, nil
+}
+
+// List calls the callback for objects having the prefix. Common prefixes are
+// listed as directories if the delimiter is not empty.
+func (c *Client) List(bucket, prefix, delimiter string, callback func(*ObjectInfo)) error {
+	token := ""
+	for {
+		query := map[string]string{"list-type": "2", "prefix": prefix}
+		if delimiter != "" {
+			query["delimiter"] = delimiter
+		}
+		if token != "" {
+			query["continuation-token"] = token
+		}
+		resp, err := c.request("GET", bucket, "", query, nil, nil)
+		if err != nil {
+			return err
+		}
+		var result struct {
+			Contents []struct {
+				Key          string    `xml:"Key"`
+				LastModified time.Time `xml:"LastModified"`
+				Size         int64     `xml:"Size"`
+			} `xml:"Contents"`
+			CommonPrefixes []struct {
+				Prefix string `xml:"Prefix"`
+			} `xml:"CommonPrefixes"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if err := decode(resp, &result); err != nil {
+			return err
+		}
+		for _, p := range result.CommonPrefixes {
+			name := path.Base(strings.TrimSuffix(p.Prefix, "/"))
+			callback(&ObjectInfo{Key: p.Prefix, name: name, dir: true})
+		}
+		for _, o := range result.Contents {
+			if o.Key == prefix && strings.HasSuffix(o.Key, "/") {
+				continue // the directory marker itself
+			}
+			callback(&ObjectInfo{Key: o.Key, name: path.Base(o.Key), size: o.Size, mtime: o.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Head returns the object information.
+func (c *Client) Head(bucket, key string) (*ObjectInfo, error) {
+	resp, err := c.request("HEAD", bucket, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	mtime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &ObjectInfo{Key: key, name: path.Base(key), size: resp.ContentLength, mtime: mtime}, nil
+}
+
+// Get returns the object content. The caller must close it.
+func (c *Client) Get(bucket, key string) (io.ReadCloser, error) {
+	resp, err := c.request("GET", bucket, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Put uploads the data to the object by a single request.
+func (c *Client) Put(bucket, key string, data []byte) error {
+	resp, err := c.request("PUT", bucket, key, nil, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Delete removes the object.
+func (c *Client) Delete(bucket, key string) error {
+	resp, err := c.request("DELETE", bucket, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// MultipartThreshold is the size to upload by the multipart upload.
+var MultipartThreshold int64 = 64 * 1024 * 1024
+
+const (
+	minPartSize = 16 * 1024 * 1024
+	maxParts    = 10000
+)
+
+// Upload uploads the content of the reader of the size. Large contents are
+// uploaded by the multipart upload and the progress is called for each part.
+func (c *Client) Upload(bucket, key string, r io.Reader, size int64, progress func(n int)) error {
+	if size < MultipartThreshold {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if err := c.Put(bucket, key, data); err != nil {
+			return err
+		}
+		progress(len(data))
+		return nil
+	}
+
+	partSize := int64(minPartSize)
+	if n := (size + maxParts - 1) / maxParts; n > partSize {
+		partSize = n
+	}
+	uploadID, err := c.createMultipart(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := c.uploadParts(bucket, key, uploadID, r, partSize, progress); err != nil {
+		if resp, e := c.request("DELETE", bucket, key, map[string]string{"uploadId": uploadID}, nil, nil); e == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *Client) createMultipart(bucket, key string) (string, error) {
+	resp, err := c.request("POST", bucket, key, map[string]string{"uploads": ""}, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := decode(resp, &result); err != nil {
+		return "", err
+	}
+	return result.UploadID, nil
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (c *Client) uploadParts(bucket, key, uploadID string, r io.Reader, partSize int64, progress func(n int)) error {
+	var parts []completedPart
+	buf := make([]byte, partSize)
+	for number := 1; ; number++ {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			break
+		} else if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		query := map[string]string{"partNumber": strconv.Itoa(number), "uploadId": uploadID}
+		resp, err := c.request("PUT", bucket, key, query, nil, buf[:n])
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		parts = append(parts, completedPart{number, resp.Header.Get("ETag")})
+		progress(n)
+		if n < len(buf) {
+			break
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	resp, err := c.request("POST", bucket, key, map[string]string{"uploadId": uploadID}, nil, body)
+	if err != nil {
+		return err
+	}
+	// the completion may fail with the status 200 and an error body
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+	e := &Error{StatusCode: resp.StatusCode}
+	if xml.Unmarshal(data, e) == nil && e.Code != "" {
+		return e
+	}
+	return nil
+}
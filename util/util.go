@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"unicode"
 
 	"github.com/mattn/go-runewidth"
 )
@@ -28,6 +30,166 @@ func ExpandPath(name string) string {
 	return name
 }
 
+// MatchPath reports whether the path matches the pattern separated by slashes.
+// The pattern "**" matches zero or more directories, and others are matched
+// by path.Match for each directory.
+func MatchPath(pattern, name string) bool {
+	p := strings.Split(filepath.ToSlash(pattern), "/")
+	s := strings.Split(filepath.ToSlash(name), "/")
+	return matchSegments(p, s)
+}
+
+func matchSegments(p, s []string) bool {
+	for len(p) > 0 {
+		if p[0] == "**" {
+			for i := 0; i <= len(s); i++ {
+				if matchSegments(p[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(s) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(p[0], s[0]); !ok {
+			return false
+		}
+		p, s = p[1:], s[1:]
+	}
+	return len(s) == 0
+}
+
+// NaturalLess reports whether a sorts before b comparing runs of digits by
+// the numeric value, such as file2 before file10.
+func NaturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			na, nb := digitsPrefix(a), digitsPrefix(b)
+			ta, tb := strings.TrimLeft(na, "0"), strings.TrimLeft(nb, "0")
+			if len(ta) != len(tb) {
+				return len(ta) < len(tb)
+			} else if ta != tb {
+				return ta < tb
+			} else if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			a, b = a[len(na):], b[len(nb):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// Scores of fuzzy matching.
+const (
+	fuzzyScoreMatch       = 16
+	fuzzyBonusBoundary    = 8
+	fuzzyBonusSeparator   = 9
+	fuzzyBonusCamel       = 7
+	fuzzyBonusConsecutive = 4
+	fuzzyPenaltyGapStart  = 5
+	fuzzyPenaltyGapExtend = 1
+)
+
+// FuzzyMatch matches the pattern characters in order, not necessarily
+// contiguous, like fzf. The match is case insensitive unless the pattern has
+// upper case letters. Returns the best score, higher for matches at word
+// boundaries and path separators, and rune positions of matched characters.
+func FuzzyMatch(pattern, s string) (int, []int, bool) {
+	pat, str, orig := []rune(pattern), []rune(s), []rune(s)
+	if len(pat) == 0 {
+		return 0, nil, true
+	}
+	if pattern == strings.ToLower(pattern) {
+		for i, c := range str {
+			str[i] = unicode.ToLower(c)
+		}
+	}
+	const none = -1 << 30
+	// score[i][j] is the best score matching pat[:i+1] with pat[i] at str[j]
+	score := make([][]int, len(pat))
+	from := make([][]int, len(pat))
+	for i := range pat {
+		score[i] = make([]int, len(str))
+		from[i] = make([]int, len(str))
+		gap, gapFrom := none, -1 // the best previous match with a gap
+		for j := range str {
+			score[i][j] = none
+			if i > 0 && j >= 2 && score[i-1][j-2] > none {
+				if g := score[i-1][j-2] - fuzzyPenaltyGapStart; g >= gap-fuzzyPenaltyGapExtend {
+					gap, gapFrom = g, j-2
+				} else {
+					gap -= fuzzyPenaltyGapExtend
+				}
+			} else if gap > none {
+				gap -= fuzzyPenaltyGapExtend
+			}
+			if str[j] != pat[i] {
+				continue
+			}
+			bonus := fuzzyScoreMatch + fuzzyBonus(orig, j)
+			if i == 0 {
+				score[i][j], from[i][j] = bonus, -1
+				continue
+			}
+			if j > 0 && score[i-1][j-1] > none {
+				score[i][j], from[i][j] = score[i-1][j-1]+fuzzyBonusConsecutive+bonus, j-1
+			}
+			if gap > none && gap+bonus > score[i][j] {
+				score[i][j], from[i][j] = gap+bonus, gapFrom
+			}
+		}
+	}
+	last := len(pat) - 1
+	best, end := none, -1
+	for j, sc := range score[last] {
+		if sc > best {
+			best, end = sc, j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	positions := make([]int, len(pat))
+	for i, j := last, end; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+	return best, positions, true
+}
+
+// fuzzyBonus returns the bonus of the character at the word boundary.
+func fuzzyBonus(s []rune, i int) int {
+	if i == 0 {
+		return fuzzyBonusBoundary
+	}
+	switch prev, c := s[i-1], s[i]; {
+	case prev == '/' || prev == '\\':
+		return fuzzyBonusSeparator
+	case strings.ContainsRune("_-. ", prev):
+		return fuzzyBonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(c),
+		!unicode.IsDigit(prev) && unicode.IsDigit(c):
+		return fuzzyBonusCamel
+	}
+	return 0
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func digitsPrefix(s string) string {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i]
+}
+
 // AbbrPath abbreviates path beginning of home directory to ~.
 func AbbrPath(name string) string {
 	home, _ := os.UserHomeDir()
@@ -201,7 +363,7 @@ func CalcSizeCount(src ...string) (int64, int) {
 	count := 0
 	for _, s := range src {
 		_ = filepath.Walk(s, func(path string, fi os.FileInfo, err error) error {
-			if fi.IsDir() || fi.Mode()&os.ModeSymlink != 0 {
+			if err != nil || fi.IsDir() || fi.Mode()&os.ModeSymlink != 0 {
 				return nil
 			}
 			size += fi.Size()
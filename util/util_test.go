@@ -3,6 +3,7 @@ package util
 import (
 	"os"
 	"path"
+	"reflect"
 	"testing"
 )
 
@@ -78,3 +79,86 @@ func TestRemoveExt(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchPath(t *testing.T) {
+	for _, d := range []struct {
+		pattern string
+		name    string
+		result  bool
+	}{
+		{"/home/user/Downloads/**", "/home/user/Downloads", true},
+		{"/home/user/Downloads/**", "/home/user/Downloads/a/b", true},
+		{"/home/user/Downloads/**", "/home/user/Documents", false},
+		{"/home/*/src", "/home/user/src", true},
+		{"/home/*/src", "/home/user/a/src", false},
+		{"/**/node_modules", "/home/user/app/node_modules", true},
+		{"/**/node_modules", "/home/user/app/node_modules/x", false},
+		{"/home/user", "/home/user", true},
+	} {
+		if result := MatchPath(d.pattern, d.name); result != d.result {
+			t.Errorf("MatchPath(%q, %q)=%v, want %v", d.pattern, d.name, result, d.result)
+		}
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	for _, d := range []struct {
+		a      string
+		b      string
+		result bool
+	}{
+		{"file2", "file10", true},
+		{"file10", "file2", false},
+		{"file02", "file2", false},
+		{"file2", "file02", true},
+		{"a1b2", "a1b10", true},
+		{"abc", "abd", true},
+		{"abc", "abc", false},
+		{"ab", "abc", true},
+		{"v1.9.1", "v1.10.0", true},
+	} {
+		if result := NaturalLess(d.a, d.b); result != d.result {
+			t.Errorf("NaturalLess(%q, %q)=%v, want %v", d.a, d.b, result, d.result)
+		}
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	for _, d := range []struct {
+		pattern   string
+		s         string
+		positions []int
+		ok        bool
+	}{
+		{"", "abc", nil, true},
+		{"abc", "aXbXc", []int{0, 2, 4}, true},
+		{"fb", "foo_bar", []int{0, 4}, true},
+		{"fb", "xfxxbfoo_bar", []int{5, 9}, true},
+		{"acb", "abc", nil, false},
+		{"ABC", "abc", nil, false},
+		{"abc", "ABC", []int{0, 1, 2}, true},
+		{"ねこ", "いぬねここ", []int{2, 3}, true},
+	} {
+		_, positions, ok := FuzzyMatch(d.pattern, d.s)
+		if ok != d.ok || !reflect.DeepEqual(positions, d.positions) {
+			t.Errorf("FuzzyMatch(%q, %q)=%v, %v, want %v, %v", d.pattern, d.s, positions, ok, d.positions, d.ok)
+		}
+	}
+
+	for _, d := range []struct {
+		pattern string
+		better  string
+		worse   string
+	}{
+		{"fb", "foo_bar", "fxxxb"},
+		{"gm", "src/go/main.go", "src/gxxm"},
+		{"abc", "abc", "a_b_c"},
+		{"fb", "fooBar", "foobar"},
+	} {
+		s1, _, _ := FuzzyMatch(d.pattern, d.better)
+		s2, _, _ := FuzzyMatch(d.pattern, d.worse)
+		if s1 <= s2 {
+			t.Errorf("FuzzyMatch(%q, %q)=%d, want more than %q=%d", d.pattern, d.better, s1, d.worse, s2)
+		}
+	}
+}